@@ -0,0 +1,54 @@
+package inspector
+
+import (
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/m-mizutani/deepalert"
+	"github.com/m-mizutani/deepalert/internal/errors"
+)
+
+// Emitter publishes the output of a Handler run (a ReportSection or a
+// newly discovered Attribute task) to wherever downstream consumers
+// expect it. It decouples HandleTask from any one transport, so the same
+// inspector logic can run behind SQS (SQSEmitter) or plain HTTP
+// (HTTPEmitter).
+type Emitter interface {
+	EmitSection(section deepalert.ReportSection) error
+	EmitAttribute(task deepalert.Task) error
+}
+
+// SQSEmitter publishes sections and attribute tasks to the two SQS queues
+// used by the original Lambda-based deployment.
+type SQSEmitter struct {
+	ContentQueueURL string
+	AttrQueueURL    string
+}
+
+// EmitSection implements Emitter.
+func (x *SQSEmitter) EmitSection(section deepalert.ReportSection) error {
+	return x.send(x.ContentQueueURL, section)
+}
+
+// EmitAttribute implements Emitter.
+func (x *SQSEmitter) EmitAttribute(task deepalert.Task) error {
+	return x.send(x.AttrQueueURL, task)
+}
+
+func (x *SQSEmitter) send(queueURL string, body interface{}) error {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return errors.Wrapf(err, "Fail to marshal emitter payload: %v", body)
+	}
+
+	_, err = newSQSClient().SendMessage(&sqs.SendMessageInput{
+		QueueUrl:    aws.String(queueURL),
+		MessageBody: aws.String(string(raw)),
+	})
+	if err != nil {
+		return errors.Wrap(err, "Fail to send SQS message").With("queueURL", queueURL)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package inspector
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/m-mizutani/deepalert"
+	"github.com/m-mizutani/deepalert/internal/errors"
+)
+
+// HTTPEmitter publishes sections and attribute tasks by POSTing them as
+// JSON to downstream endpoints, for inspectors deployed outside of
+// SQS/Lambda (Cloud Run, Kubernetes, on-prem).
+type HTTPEmitter struct {
+	SectionURL   string
+	AttributeURL string
+	Client       *http.Client
+}
+
+func (x *HTTPEmitter) client() *http.Client {
+	if x.Client != nil {
+		return x.Client
+	}
+	return http.DefaultClient
+}
+
+// EmitSection implements Emitter.
+func (x *HTTPEmitter) EmitSection(section deepalert.ReportSection) error {
+	return x.post(x.SectionURL, section)
+}
+
+// EmitAttribute implements Emitter.
+func (x *HTTPEmitter) EmitAttribute(task deepalert.Task) error {
+	return x.post(x.AttributeURL, task)
+}
+
+func (x *HTTPEmitter) post(url string, body interface{}) error {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return errors.Wrapf(err, "Fail to marshal emitter payload: %v", body)
+	}
+
+	resp, err := x.client().Post(url, "application/json", bytes.NewReader(raw))
+	if err != nil {
+		return errors.Wrap(err, "Fail to POST emitter payload").With("url", url)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return errors.New("Emitter endpoint returned non-2xx status").With("url", url).With("status", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// StartHTTP runs the inspector as a plain HTTP service listening on addr.
+// Each POST of a deepalert.Task JSON body runs args.Handler, publishes the
+// resulting sections/attributes via args.emitter() exactly as Start does,
+// and replies with the deepalert.TaskResult as JSON. This lets an
+// inspector be deployed to Cloud Run, Kubernetes or on-prem instead of
+// only as an SQS-triggered Lambda.
+func StartHTTP(addr string, args Arguments) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var task deepalert.Task
+		if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
+			http.Error(w, "invalid task: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err := args.Handler(r.Context(), task.Attribute)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if result == nil {
+			result = &deepalert.TaskResult{}
+		}
+
+		if err := emitResult(args, task, result); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(result)
+	})
+
+	return http.ListenAndServe(addr, mux)
+}
@@ -0,0 +1,155 @@
+package inspector
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/m-mizutani/deepalert"
+	"github.com/m-mizutani/deepalert/internal/errors"
+	"github.com/m-mizutani/deepalert/internal/handler"
+)
+
+// HandlerFunc is the signature inspector authors implement: given a single
+// deepalert.Attribute, it returns whatever report content and newly
+// discovered attributes the inspector produced for it.
+type HandlerFunc func(ctx context.Context, attr deepalert.Attribute) (*deepalert.TaskResult, error)
+
+// Arguments configures an inspector run, regardless of which transport
+// (SQS-triggered Lambda or plain HTTP) delivers the Task.
+type Arguments struct {
+	Handler         HandlerFunc
+	Author          string
+	AttrQueueURL    string
+	ContentQueueURL string
+
+	// DLQueueURL, if set, is where Start drains a task that failed with a
+	// non-retryable error, exactly like handler.Arguments.DLQueueURL does
+	// for the StepFunctions-driven handlers. Left empty, such failures are
+	// just logged and dropped.
+	DLQueueURL string
+
+	// Emitter overrides how ReportSection and new Attribute messages are
+	// published. When nil, an SQSEmitter built from ContentQueueURL and
+	// AttrQueueURL is used, preserving the original Lambda behavior.
+	Emitter Emitter
+}
+
+func (x *Arguments) emitter() Emitter {
+	if x.Emitter != nil {
+		return x.Emitter
+	}
+	return &SQSEmitter{ContentQueueURL: x.ContentQueueURL, AttrQueueURL: x.AttrQueueURL}
+}
+
+// sqsClient is the subset of the SQS API the inspector needs, abstracted
+// so tests can inject a fake.
+type sqsClient interface {
+	SendMessage(*sqs.SendMessageInput) (*sqs.SendMessageOutput, error)
+}
+
+var newSQSClient = func() sqsClient {
+	return sqs.New(session.New())
+}
+
+// InjectNewSQSClient replaces the SQS client used by SQSEmitter. Intended
+// for tests.
+func InjectNewSQSClient(client sqsClient) {
+	newSQSClient = func() sqsClient { return client }
+}
+
+// FixNewSQSClient restores the default SQS client constructor, undoing
+// InjectNewSQSClient.
+func FixNewSQSClient() {
+	newSQSClient = func() sqsClient {
+		return sqs.New(session.New())
+	}
+}
+
+// HandleTask runs args.Handler against task.Attribute and publishes the
+// resulting ReportSection and any new Attribute tasks via args.emitter().
+// It is transport-agnostic: Start feeds it from SQS, StartHTTP feeds it
+// from a POSTed Task.
+func HandleTask(ctx context.Context, args Arguments, task deepalert.Task) error {
+	result, err := args.Handler(ctx, task.Attribute)
+	if err != nil {
+		wrapped := errors.Wrap(err, "Fail to handle task").With("task", task)
+		if daErr, ok := err.(*errors.Error); ok {
+			wrapped.WithKind(daErr.Kind)
+		}
+		return wrapped
+	}
+
+	return emitResult(args, task, result)
+}
+
+func emitResult(args Arguments, task deepalert.Task, result *deepalert.TaskResult) error {
+	if result == nil {
+		return nil
+	}
+
+	emitter := args.emitter()
+
+	for _, content := range result.Contents {
+		section := deepalert.ReportSection{
+			ReportID:  task.ReportID,
+			Author:    args.Author,
+			Attribute: task.Attribute,
+			Content:   content,
+		}
+		if err := emitter.EmitSection(section); err != nil {
+			return err
+		}
+	}
+
+	for _, newAttr := range result.NewAttributes {
+		newTask := deepalert.Task{
+			ReportID:  task.ReportID,
+			Attribute: newAttr,
+		}
+		if err := emitter.EmitAttribute(newTask); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StartTest runs args.Handler directly against attr without any queueing,
+// for use in inspector unit tests.
+func StartTest(args Arguments, attr deepalert.Attribute) (*deepalert.TaskResult, error) {
+	return args.Handler(context.Background(), attr)
+}
+
+// Start boots the inspector as an SQS-triggered Lambda function, invoking
+// args.Handler for each deepalert.Task delivered via the Lambda event. Use
+// StartHTTP instead to run the same Handler as a plain HTTP service.
+//
+// A failing record is routed through handler.ClassifyFailure, the same
+// taxonomy/DLQ logic handler.StartLambda uses: a retryable error is
+// returned so Lambda retries the invocation, a non-retryable one is
+// drained to args.DLQueueURL (if set) and swallowed.
+func Start(args Arguments) {
+	lambda.Start(func(ctx context.Context, event events.SQSEvent) error {
+		for _, record := range event.Records {
+			var task deepalert.Task
+			if err := json.Unmarshal([]byte(record.Body), &task); err != nil {
+				wrapped := errors.Wrap(err, "Fail to unmarshal SQS message body").WithKind(errors.KindInvalidInput).With("body", record.Body)
+				if err := handler.ClassifyFailure(args.DLQueueURL, record, wrapped); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := HandleTask(ctx, args, task); err != nil {
+				if err := handler.ClassifyFailure(args.DLQueueURL, task, err); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,87 @@
+// Package testkit lets inspector authors regression-test a HandlerFunc
+// offline: Record captures what one HandleTask run emits into a Fixture,
+// Replay feeds saved fixtures back through the current Handler and diffs
+// the result against a golden file, and Repository is an in-memory
+// adaptor.Repository for exercising RepositoryService without LocalStack.
+package testkit
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/m-mizutani/deepalert"
+	"github.com/m-mizutani/deepalert/inspector"
+	"github.com/m-mizutani/deepalert/internal/errors"
+)
+
+// Fixture is everything one HandleTask run produced: the Task it was given
+// and the ReportSection/new-Attribute Task messages it emitted.
+type Fixture struct {
+	Task       deepalert.Task
+	Sections   []deepalert.ReportSection
+	Attributes []deepalert.Task
+}
+
+// Recorder is an inspector.Emitter that captures every EmitSection and
+// EmitAttribute call instead of publishing them, so a HandleTask run can be
+// turned into a Fixture. Use Record rather than constructing one directly.
+type Recorder struct {
+	Sections   []deepalert.ReportSection
+	Attributes []deepalert.Task
+}
+
+// EmitSection implements inspector.Emitter.
+func (x *Recorder) EmitSection(section deepalert.ReportSection) error {
+	x.Sections = append(x.Sections, section)
+	return nil
+}
+
+// EmitAttribute implements inspector.Emitter.
+func (x *Recorder) EmitAttribute(task deepalert.Task) error {
+	x.Attributes = append(x.Attributes, task)
+	return nil
+}
+
+// Record runs args.Handler against task with a Recorder standing in for
+// args.Emitter (any Emitter already set on args is overridden) and returns
+// what it captured as a Fixture.
+func Record(ctx context.Context, args inspector.Arguments, task deepalert.Task) (*Fixture, error) {
+	rec := &Recorder{}
+	args.Emitter = rec
+
+	if err := inspector.HandleTask(ctx, args, task); err != nil {
+		return nil, err
+	}
+
+	return &Fixture{Task: task, Sections: rec.Sections, Attributes: rec.Attributes}, nil
+}
+
+// Save writes fixture to path as indented JSON.
+func (x *Fixture) Save(path string) error {
+	raw, err := json.MarshalIndent(x, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "Fail to marshal fixture")
+	}
+
+	if err := os.WriteFile(path, raw, 0644); err != nil {
+		return errors.Wrapf(err, "Fail to write fixture: %s", path)
+	}
+
+	return nil
+}
+
+// LoadFixture reads back a Fixture previously written by Fixture.Save.
+func LoadFixture(path string) (*Fixture, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Fail to read fixture: %s", path)
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(raw, &fixture); err != nil {
+		return nil, errors.Wrapf(err, "Fail to unmarshal fixture: %s", path)
+	}
+
+	return &fixture, nil
+}
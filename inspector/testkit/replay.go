@@ -0,0 +1,119 @@
+package testkit
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/m-mizutani/deepalert"
+	"github.com/m-mizutani/deepalert/inspector"
+	"github.com/m-mizutani/deepalert/internal/errors"
+)
+
+// maxReplayDepth bounds how many rounds of self fan-out Replay follows
+// before giving up, so a Handler that always emits a new attribute can't
+// loop forever.
+const maxReplayDepth = 10
+
+// Mask rewrites replayed sections in place before they are compared
+// against the golden file, e.g. to blank out timestamps or other fields
+// that are expected to differ between runs. DefaultMask covers the common
+// case.
+type Mask func(sections []deepalert.ReportSection)
+
+// DefaultMask zeroes every section's Attribute.Timestamp, the one field
+// HandleTask output varies on by default between otherwise-identical runs.
+func DefaultMask(sections []deepalert.ReportSection) {
+	for i := range sections {
+		sections[i].Attribute.Timestamp = nil
+	}
+}
+
+// Replay feeds every Task fixture saved under fixtureDir (see Record, or
+// hand-authored fixture JSON) through args.Handler, following each run's
+// own new-Attribute fan-out for up to maxReplayDepth rounds, then compares
+// the resulting ReportSections (sorted for determinism, then passed
+// through mask if non-nil) against fixtureDir/golden.json.
+//
+// Set the UPDATE_GOLDEN=1 environment variable to (re)write golden.json
+// from the current run instead of comparing against it.
+func Replay(ctx context.Context, fixtureDir string, args inspector.Arguments, mask Mask) error {
+	tasks, err := loadSeedTasks(fixtureDir)
+	if err != nil {
+		return err
+	}
+
+	var sections []deepalert.ReportSection
+	for round := 0; len(tasks) > 0 && round < maxReplayDepth; round++ {
+		var next []deepalert.Task
+		for _, task := range tasks {
+			fixture, err := Record(ctx, args, task)
+			if err != nil {
+				return err
+			}
+			sections = append(sections, fixture.Sections...)
+			next = append(next, fixture.Attributes...)
+		}
+		tasks = next
+	}
+
+	sort.Slice(sections, func(i, j int) bool {
+		return sections[i].Attribute.Hash()+sections[i].Author < sections[j].Attribute.Hash()+sections[j].Author
+	})
+
+	if mask != nil {
+		mask(sections)
+	}
+
+	actual, err := json.MarshalIndent(sections, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "Fail to marshal replayed sections")
+	}
+
+	goldenPath := filepath.Join(fixtureDir, "golden.json")
+	if os.Getenv("UPDATE_GOLDEN") != "" {
+		if err := os.WriteFile(goldenPath, actual, 0644); err != nil {
+			return errors.Wrapf(err, "Fail to write golden file: %s", goldenPath)
+		}
+		return nil
+	}
+
+	golden, err := os.ReadFile(goldenPath)
+	if err != nil {
+		return errors.Wrapf(err, "Fail to read golden file: %s", goldenPath)
+	}
+
+	if string(golden) != string(actual) {
+		return errors.New("Replayed sections do not match golden file").
+			With("goldenPath", goldenPath).With("actual", string(actual))
+	}
+
+	return nil
+}
+
+// loadSeedTasks reads every *.json fixture directly under fixtureDir
+// (golden.json is not a fixture and is skipped) and returns the Task each
+// one was recorded against, the seed for Replay's first round.
+func loadSeedTasks(fixtureDir string) ([]deepalert.Task, error) {
+	entries, err := os.ReadDir(fixtureDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Fail to read fixture dir: %s", fixtureDir)
+	}
+
+	var tasks []deepalert.Task
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == "golden.json" || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		fixture, err := LoadFixture(filepath.Join(fixtureDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, fixture.Task)
+	}
+
+	return tasks, nil
+}
@@ -0,0 +1,125 @@
+package testkit
+
+import (
+	"time"
+
+	"github.com/m-mizutani/deepalert"
+	"github.com/m-mizutani/deepalert/internal/adaptor"
+	"github.com/m-mizutani/deepalert/internal/models"
+)
+
+// Repository is a minimal in-memory adaptor.Repository, letting
+// service.RepositoryService be exercised end-to-end in unit tests without
+// LocalStack or a real database.
+type Repository struct {
+	alertEntries map[string]*models.AlertEntry
+	alertCaches  map[string][]*models.AlertCache
+	sections     map[string][]*models.ReportSectionRecord
+	attrCaches   map[string][]*models.AttributeCache
+	attrKeys     map[string]bool
+	filters      map[string]*models.AttributeFilter
+	chains       map[string]*models.ReportChain
+}
+
+// NewRepository returns an empty Repository.
+func NewRepository() *Repository {
+	return &Repository{
+		alertEntries: map[string]*models.AlertEntry{},
+		alertCaches:  map[string][]*models.AlertCache{},
+		sections:     map[string][]*models.ReportSectionRecord{},
+		attrCaches:   map[string][]*models.AttributeCache{},
+		attrKeys:     map[string]bool{},
+		filters:      map[string]*models.AttributeFilter{},
+		chains:       map[string]*models.ReportChain{},
+	}
+}
+
+func recordKey(pk, sk string) string { return pk + "/" + sk }
+
+// PutAlertEntry implements adaptor.Repository.
+func (x *Repository) PutAlertEntry(entry *models.AlertEntry, now time.Time) error {
+	key := recordKey(entry.PKey, entry.SKey)
+	if _, ok := x.alertEntries[key]; ok {
+		return adaptor.ErrAlreadyExists
+	}
+	x.alertEntries[key] = entry
+	return nil
+}
+
+// GetAlertEntry implements adaptor.Repository.
+func (x *Repository) GetAlertEntry(pk, sk string) (*models.AlertEntry, error) {
+	return x.alertEntries[recordKey(pk, sk)], nil
+}
+
+// PutAlertCache implements adaptor.Repository.
+func (x *Repository) PutAlertCache(cache *models.AlertCache) error {
+	x.alertCaches[cache.PKey] = append(x.alertCaches[cache.PKey], cache)
+	return nil
+}
+
+// GetAlertCaches implements adaptor.Repository.
+func (x *Repository) GetAlertCaches(pk string) ([]*models.AlertCache, error) {
+	return x.alertCaches[pk], nil
+}
+
+// PutReportSectionRecord implements adaptor.Repository.
+func (x *Repository) PutReportSectionRecord(record *models.ReportSectionRecord) error {
+	for _, existing := range x.sections[record.PKey] {
+		if existing.SKey == record.SKey {
+			return adaptor.ErrAlreadyExists
+		}
+	}
+	x.sections[record.PKey] = append(x.sections[record.PKey], record)
+	return nil
+}
+
+// GetReportSection implements adaptor.Repository.
+func (x *Repository) GetReportSection(pk string) ([]*models.ReportSectionRecord, error) {
+	return x.sections[pk], nil
+}
+
+// PutAttributeCache implements adaptor.Repository.
+func (x *Repository) PutAttributeCache(cache *models.AttributeCache, now time.Time) error {
+	key := recordKey(cache.PKey, cache.SKey)
+	if x.attrKeys[key] {
+		return adaptor.ErrAlreadyExists
+	}
+	x.attrKeys[key] = true
+	x.attrCaches[cache.PKey] = append(x.attrCaches[cache.PKey], cache)
+	return nil
+}
+
+// GetAttributeCaches implements adaptor.Repository.
+func (x *Repository) GetAttributeCaches(pk string) ([]*models.AttributeCache, error) {
+	return x.attrCaches[pk], nil
+}
+
+// GetAttributeFilter implements adaptor.Repository.
+func (x *Repository) GetAttributeFilter(reportID deepalert.ReportID) (*models.AttributeFilter, error) {
+	return x.filters["attrfilter/"+string(reportID)], nil
+}
+
+// PutAttributeFilter implements adaptor.Repository.
+func (x *Repository) PutAttributeFilter(filter *models.AttributeFilter, expectedVersion int) error {
+	existing := x.filters[filter.PKey]
+	if (existing == nil && expectedVersion != 0) || (existing != nil && existing.Version != expectedVersion) {
+		return adaptor.ErrVersionConflict
+	}
+	x.filters[filter.PKey] = filter
+	return nil
+}
+
+// GetReportChain implements adaptor.Repository.
+func (x *Repository) GetReportChain(reportID deepalert.ReportID) (*models.ReportChain, error) {
+	return x.chains["reportchain/"+string(reportID)], nil
+}
+
+// PutReportChain implements adaptor.Repository.
+func (x *Repository) PutReportChain(chain *models.ReportChain, expectedVersion int) error {
+	existing := x.chains[chain.PKey]
+	if (existing == nil && expectedVersion != 0) || (existing != nil && existing.Version != expectedVersion) {
+		return adaptor.ErrVersionConflict
+	}
+	x.chains[chain.PKey] = chain
+	return nil
+}
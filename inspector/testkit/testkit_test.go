@@ -0,0 +1,95 @@
+package testkit_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/deepalert"
+	"github.com/m-mizutani/deepalert/inspector"
+	"github.com/m-mizutani/deepalert/inspector/testkit"
+	"github.com/m-mizutani/deepalert/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dummyHandler(ctx context.Context, attr deepalert.Attribute) (*deepalert.TaskResult, error) {
+	if attr.Type != deepalert.TypeIPAddr {
+		return nil, nil
+	}
+
+	return &deepalert.TaskResult{
+		Contents: []deepalert.ReportContent{&deepalert.ReportHost{IPAddr: []string{attr.Value}}},
+	}, nil
+}
+
+func TestRecordSaveAndLoadFixture(t *testing.T) {
+	args := inspector.Arguments{Handler: dummyHandler, Author: "dummy"}
+	task := deepalert.Task{
+		ReportID:  deepalert.ReportID("report-1"),
+		Attribute: deepalert.Attribute{Type: deepalert.TypeIPAddr, Key: "SrcIP", Value: "10.0.0.1"},
+	}
+
+	fixture, err := testkit.Record(context.Background(), args, task)
+	require.NoError(t, err)
+	require.Len(t, fixture.Sections, 1)
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	require.NoError(t, fixture.Save(path))
+
+	loaded, err := testkit.LoadFixture(path)
+	require.NoError(t, err)
+	assert.Equal(t, fixture.Task, loaded.Task)
+	assert.Equal(t, len(fixture.Sections), len(loaded.Sections))
+}
+
+func TestReplayMatchesGoldenUntilHandlerChanges(t *testing.T) {
+	dir := t.TempDir()
+	args := inspector.Arguments{Handler: dummyHandler, Author: "dummy"}
+
+	task := deepalert.Task{
+		ReportID:  deepalert.ReportID("report-2"),
+		Attribute: deepalert.Attribute{Type: deepalert.TypeIPAddr, Key: "SrcIP", Value: "10.0.0.2"},
+	}
+	fixture, err := testkit.Record(context.Background(), args, task)
+	require.NoError(t, err)
+	require.NoError(t, fixture.Save(filepath.Join(dir, "seed.json")))
+
+	require.NoError(t, os.Setenv("UPDATE_GOLDEN", "1"))
+	require.NoError(t, testkit.Replay(context.Background(), dir, args, testkit.DefaultMask))
+	require.NoError(t, os.Unsetenv("UPDATE_GOLDEN"))
+
+	assert.NoError(t, testkit.Replay(context.Background(), dir, args, testkit.DefaultMask))
+
+	otherHandler := func(ctx context.Context, attr deepalert.Attribute) (*deepalert.TaskResult, error) {
+		return nil, nil
+	}
+	args.Handler = otherHandler
+	assert.Error(t, testkit.Replay(context.Background(), dir, args, testkit.DefaultMask))
+}
+
+func TestRepositoryServiceEndToEnd(t *testing.T) {
+	repo := testkit.NewRepository()
+	svc := service.NewRepositoryService(repo, 3600)
+	now := time.Now()
+
+	reportID := deepalert.ReportID("report-3")
+	attr := deepalert.Attribute{Type: deepalert.TypeIPAddr, Key: "SrcIP", Value: "10.0.0.3"}
+
+	ok, err := svc.PutAttributeCache(reportID, attr, now)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = svc.PutAttributeCache(reportID, attr, now)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	section := deepalert.ReportSection{ReportID: reportID, Author: "dummy", Content: &deepalert.ReportHost{IPAddr: []string{"10.0.0.3"}}}
+	require.NoError(t, svc.SaveReportSection(section, now))
+
+	sections, err := svc.FetchReportSection(reportID)
+	require.NoError(t, err)
+	assert.Len(t, sections, 1)
+}
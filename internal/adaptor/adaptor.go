@@ -0,0 +1,67 @@
+// Package adaptor defines the storage interface RepositoryService is
+// built on, so the DynamoDB-shaped deployment used in Lambda can be
+// swapped for a SQL-backed one (see sqlrepo) in on-prem deployments.
+package adaptor
+
+import (
+	"errors"
+	"time"
+
+	"github.com/m-mizutani/deepalert"
+	"github.com/m-mizutani/deepalert/internal/models"
+)
+
+// ErrAlreadyExists is returned by PutAlertEntry, PutAttributeCache and
+// PutReportSectionRecord when a record already exists under the given key.
+// Repository implementations
+// must return this sentinel (directly, or wrapped so errors.Is finds it)
+// instead of a backend-specific conditional-check error, so
+// RepositoryService can treat the "already exists" case the same way
+// regardless of which Repository backs it.
+var ErrAlreadyExists = errors.New("record already exists")
+
+// ErrVersionConflict is returned by PutAttributeFilter when the stored
+// record's version no longer matches expectedVersion, meaning another
+// writer updated it concurrently.
+var ErrVersionConflict = errors.New("attribute filter version conflict")
+
+// Repository is the storage contract RepositoryService depends on. A
+// DynamoDB implementation and a SQL one (sqlrepo) both satisfy it.
+type Repository interface {
+	PutAlertEntry(entry *models.AlertEntry, now time.Time) error
+	GetAlertEntry(pk, sk string) (*models.AlertEntry, error)
+
+	PutAlertCache(cache *models.AlertCache) error
+	GetAlertCaches(pk string) ([]*models.AlertCache, error)
+
+	// PutReportSectionRecord stores record, keyed by (PKey, SKey) where
+	// SKey is derived from the section's content hash. It returns
+	// ErrAlreadyExists if that key is already taken, so a retried write of
+	// an already-saved section (e.g. a re-run Lambda invocation) is
+	// rejected instead of stored as a duplicate.
+	PutReportSectionRecord(record *models.ReportSectionRecord) error
+	// GetReportSection returns every section stored under pk in no
+	// particular order; callers that need chain order must sort on
+	// ReportSectionRecord.Seq themselves.
+	GetReportSection(pk string) ([]*models.ReportSectionRecord, error)
+
+	PutAttributeCache(cache *models.AttributeCache, now time.Time) error
+	GetAttributeCaches(pk string) ([]*models.AttributeCache, error)
+
+	// GetAttributeFilter returns the cuckoo filter blob for reportID, or
+	// (nil, nil) if none has been stored yet.
+	GetAttributeFilter(reportID deepalert.ReportID) (*models.AttributeFilter, error)
+	// PutAttributeFilter stores filter, requiring the existing record's
+	// version (0 if none exists yet) to equal expectedVersion. It returns
+	// ErrVersionConflict on a mismatch.
+	PutAttributeFilter(filter *models.AttributeFilter, expectedVersion int) error
+
+	// GetReportChain returns the hash chain head for reportID, or
+	// (nil, nil) if no section has been chained yet.
+	GetReportChain(reportID deepalert.ReportID) (*models.ReportChain, error)
+	// PutReportChain stores chain, requiring the existing record's version
+	// (0 if none exists yet) to equal expectedVersion. It returns
+	// ErrVersionConflict on a mismatch, so callers can retry with the
+	// now-current head.
+	PutReportChain(chain *models.ReportChain, expectedVersion int) error
+}
@@ -0,0 +1,118 @@
+// Package adaptortest provides a conformance suite that exercises any
+// adaptor.Repository implementation (DynamoDB, sqlrepo, ...) through the
+// same scenarios, so a new backend only needs to prove it satisfies the
+// interface's contract once.
+package adaptortest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/deepalert"
+	"github.com/m-mizutani/deepalert/internal/adaptor"
+	"github.com/m-mizutani/deepalert/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunConformanceTests runs every scenario in the suite against a fresh
+// repository obtained from newRepo for each t.Run.
+func RunConformanceTests(t *testing.T, newRepo func(t *testing.T) adaptor.Repository) {
+	t.Run("AlertEntry is created once and read back", func(t *testing.T) {
+		repo := newRepo(t)
+		now := time.Now()
+
+		entry := &models.AlertEntry{
+			RecordBase: models.RecordBase{PKey: "alertmap/x", SKey: "Fixed", ExpiresAt: now.Add(time.Hour).Unix(), CreatedAt: now},
+			ReportID:   deepalert.ReportID("report-1"),
+		}
+
+		require.NoError(t, repo.PutAlertEntry(entry, now))
+		assert.ErrorIs(t, repo.PutAlertEntry(entry, now), adaptor.ErrAlreadyExists)
+
+		got, err := repo.GetAlertEntry(entry.PKey, entry.SKey)
+		require.NoError(t, err)
+		assert.Equal(t, entry.ReportID, got.ReportID)
+	})
+
+	t.Run("AlertCache accumulates multiple entries under one pkey", func(t *testing.T) {
+		repo := newRepo(t)
+		now := time.Now()
+
+		for i := 0; i < 3; i++ {
+			cache := &models.AlertCache{PKey: "alert/report-2", SKey: "cache/" + string(rune('a'+i)), AlertData: []byte("{}"), ExpiresAt: now.Add(time.Hour).Unix()}
+			require.NoError(t, repo.PutAlertCache(cache))
+		}
+
+		caches, err := repo.GetAlertCaches("alert/report-2")
+		require.NoError(t, err)
+		assert.Equal(t, 3, len(caches))
+	})
+
+	t.Run("AttributeCache rejects the same attribute twice", func(t *testing.T) {
+		repo := newRepo(t)
+		now := time.Now()
+
+		cache := &models.AttributeCache{
+			RecordBase: models.RecordBase{PKey: "attribute/report-3", SKey: "hash-1", ExpiresAt: now.Add(time.Hour).Unix()},
+			Timestamp:  now,
+			AttrKey:    "SrcIP",
+			AttrType:   string(deepalert.TypeIPAddr),
+			AttrValue:  "10.0.0.1",
+		}
+
+		require.NoError(t, repo.PutAttributeCache(cache, now))
+		assert.ErrorIs(t, repo.PutAttributeCache(cache, now), adaptor.ErrAlreadyExists)
+
+		caches, err := repo.GetAttributeCaches("attribute/report-3")
+		require.NoError(t, err)
+		assert.Equal(t, 1, len(caches))
+	})
+
+	t.Run("ReportSectionRecord rejects the same section twice", func(t *testing.T) {
+		repo := newRepo(t)
+		now := time.Now()
+
+		record := &models.ReportSectionRecord{
+			RecordBase:  models.RecordBase{PKey: "content/report-5", SKey: "section-hash-1", ExpiresAt: now.Add(time.Hour).Unix(), CreatedAt: now},
+			Data:        []byte("{}"),
+			SectionHash: "section-hash-1",
+			Seq:         1,
+		}
+
+		require.NoError(t, repo.PutReportSectionRecord(record))
+		assert.ErrorIs(t, repo.PutReportSectionRecord(record), adaptor.ErrAlreadyExists)
+
+		records, err := repo.GetReportSection("content/report-5")
+		require.NoError(t, err)
+		assert.Equal(t, 1, len(records))
+	})
+
+	t.Run("ReportChain extends under the expected version and rejects a stale one", func(t *testing.T) {
+		repo := newRepo(t)
+
+		got, err := repo.GetReportChain(deepalert.ReportID("report-4"))
+		require.NoError(t, err)
+		assert.Nil(t, got)
+
+		first := &models.ReportChain{
+			RecordBase: models.RecordBase{PKey: "reportchain/report-4", SKey: "Fixed"},
+			PrevHash:   "hash-1",
+			Version:    1,
+		}
+		require.NoError(t, repo.PutReportChain(first, 0))
+		assert.ErrorIs(t, repo.PutReportChain(first, 0), adaptor.ErrVersionConflict)
+
+		second := &models.ReportChain{
+			RecordBase: models.RecordBase{PKey: "reportchain/report-4", SKey: "Fixed"},
+			PrevHash:   "hash-2",
+			Version:    2,
+		}
+		require.NoError(t, repo.PutReportChain(second, 1))
+
+		got, err = repo.GetReportChain(deepalert.ReportID("report-4"))
+		require.NoError(t, err)
+		assert.Equal(t, "hash-2", got.PrevHash)
+		assert.Equal(t, 2, got.Version)
+	})
+}
@@ -0,0 +1,268 @@
+package adaptor
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/m-mizutani/deepalert"
+	"github.com/m-mizutani/deepalert/internal/errors"
+	"github.com/m-mizutani/deepalert/internal/models"
+)
+
+// DynamoRepository implements Repository against a single DynamoDB table,
+// keyed by the PKey/SKey pair every models record carries.
+type DynamoRepository struct {
+	client dynamodbiface.DynamoDBAPI
+	table  string
+}
+
+// NewDynamoRepository opens a DynamoRepository against table in the AWS
+// session's default region.
+func NewDynamoRepository(table string) *DynamoRepository {
+	return &DynamoRepository{
+		client: dynamodb.New(session.New()),
+		table:  table,
+	}
+}
+
+func (x *DynamoRepository) putIfNotExists(item interface{}) error {
+	av, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		return errors.Wrap(err, "Fail to marshal DynamoDB item")
+	}
+
+	_, err = x.client.PutItem(&dynamodb.PutItemInput{
+		TableName:           aws.String(x.table),
+		Item:                av,
+		ConditionExpression: aws.String("attribute_not_exists(PKey) AND attribute_not_exists(SKey)"),
+	})
+
+	if err != nil {
+		var condErr *dynamodb.ConditionalCheckFailedException
+		if stderrors.As(err, &condErr) {
+			return ErrAlreadyExists
+		}
+		return errors.Wrap(err, "Fail to put DynamoDB item")
+	}
+
+	return nil
+}
+
+// PutAlertEntry implements Repository.
+func (x *DynamoRepository) PutAlertEntry(entry *models.AlertEntry, now time.Time) error {
+	return x.putIfNotExists(entry)
+}
+
+// GetAlertEntry implements Repository.
+func (x *DynamoRepository) GetAlertEntry(pk, sk string) (*models.AlertEntry, error) {
+	var entry models.AlertEntry
+	if err := x.getItem(pk, sk, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// PutAlertCache implements Repository.
+func (x *DynamoRepository) PutAlertCache(cache *models.AlertCache) error {
+	av, err := dynamodbattribute.MarshalMap(cache)
+	if err != nil {
+		return errors.Wrap(err, "Fail to marshal DynamoDB item")
+	}
+	if _, err := x.client.PutItem(&dynamodb.PutItemInput{TableName: aws.String(x.table), Item: av}); err != nil {
+		return errors.Wrap(err, "Fail to put DynamoDB item")
+	}
+	return nil
+}
+
+// GetAlertCaches implements Repository.
+func (x *DynamoRepository) GetAlertCaches(pk string) ([]*models.AlertCache, error) {
+	var caches []*models.AlertCache
+	if err := x.queryByPKey(pk, &caches); err != nil {
+		return nil, err
+	}
+	return caches, nil
+}
+
+// PutReportSectionRecord implements Repository. SKey is derived from the
+// section's content hash (see toReportSectionRecord in internal/service),
+// so a retried write of an already-saved section returns ErrAlreadyExists
+// instead of storing a duplicate.
+func (x *DynamoRepository) PutReportSectionRecord(record *models.ReportSectionRecord) error {
+	return x.putIfNotExists(record)
+}
+
+// GetReportSection implements Repository.
+func (x *DynamoRepository) GetReportSection(pk string) ([]*models.ReportSectionRecord, error) {
+	var records []*models.ReportSectionRecord
+	if err := x.queryByPKey(pk, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// PutAttributeCache implements Repository.
+func (x *DynamoRepository) PutAttributeCache(cache *models.AttributeCache, now time.Time) error {
+	return x.putIfNotExists(cache)
+}
+
+// GetAttributeCaches implements Repository.
+func (x *DynamoRepository) GetAttributeCaches(pk string) ([]*models.AttributeCache, error) {
+	var caches []*models.AttributeCache
+	if err := x.queryByPKey(pk, &caches); err != nil {
+		return nil, err
+	}
+	return caches, nil
+}
+
+func attrFilterKey(reportID deepalert.ReportID) string {
+	return fmt.Sprintf("attrfilter/%s", reportID)
+}
+
+// GetAttributeFilter implements Repository.
+func (x *DynamoRepository) GetAttributeFilter(reportID deepalert.ReportID) (*models.AttributeFilter, error) {
+	resp, err := x.client.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(x.table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"PKey": {S: aws.String(attrFilterKey(reportID))},
+			"SKey": {S: aws.String("Fixed")},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Fail to get attribute filter")
+	}
+	if len(resp.Item) == 0 {
+		return nil, nil
+	}
+
+	var filter models.AttributeFilter
+	if err := dynamodbattribute.UnmarshalMap(resp.Item, &filter); err != nil {
+		return nil, errors.Wrap(err, "Fail to unmarshal attribute filter")
+	}
+	return &filter, nil
+}
+
+// PutAttributeFilter implements Repository.
+func (x *DynamoRepository) PutAttributeFilter(filter *models.AttributeFilter, expectedVersion int) error {
+	av, err := dynamodbattribute.MarshalMap(filter)
+	if err != nil {
+		return errors.Wrap(err, "Fail to marshal attribute filter")
+	}
+
+	input := &dynamodb.PutItemInput{TableName: aws.String(x.table), Item: av}
+	if expectedVersion == 0 {
+		input.ConditionExpression = aws.String("attribute_not_exists(PKey)")
+	} else {
+		input.ConditionExpression = aws.String("Version = :expected")
+		input.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{
+			":expected": {N: aws.String(strconv.Itoa(expectedVersion))},
+		}
+	}
+
+	if _, err := x.client.PutItem(input); err != nil {
+		var condErr *dynamodb.ConditionalCheckFailedException
+		if stderrors.As(err, &condErr) {
+			return ErrVersionConflict
+		}
+		return errors.Wrap(err, "Fail to put attribute filter")
+	}
+
+	return nil
+}
+
+func reportChainKey(reportID deepalert.ReportID) string {
+	return fmt.Sprintf("reportchain/%s", reportID)
+}
+
+// GetReportChain implements Repository.
+func (x *DynamoRepository) GetReportChain(reportID deepalert.ReportID) (*models.ReportChain, error) {
+	resp, err := x.client.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(x.table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"PKey": {S: aws.String(reportChainKey(reportID))},
+			"SKey": {S: aws.String("Fixed")},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Fail to get report chain")
+	}
+	if len(resp.Item) == 0 {
+		return nil, nil
+	}
+
+	var chain models.ReportChain
+	if err := dynamodbattribute.UnmarshalMap(resp.Item, &chain); err != nil {
+		return nil, errors.Wrap(err, "Fail to unmarshal report chain")
+	}
+	return &chain, nil
+}
+
+// PutReportChain implements Repository.
+func (x *DynamoRepository) PutReportChain(chain *models.ReportChain, expectedVersion int) error {
+	av, err := dynamodbattribute.MarshalMap(chain)
+	if err != nil {
+		return errors.Wrap(err, "Fail to marshal report chain")
+	}
+
+	input := &dynamodb.PutItemInput{TableName: aws.String(x.table), Item: av}
+	if expectedVersion == 0 {
+		input.ConditionExpression = aws.String("attribute_not_exists(PKey)")
+	} else {
+		input.ConditionExpression = aws.String("Version = :expected")
+		input.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{
+			":expected": {N: aws.String(strconv.Itoa(expectedVersion))},
+		}
+	}
+
+	if _, err := x.client.PutItem(input); err != nil {
+		var condErr *dynamodb.ConditionalCheckFailedException
+		if stderrors.As(err, &condErr) {
+			return ErrVersionConflict
+		}
+		return errors.Wrap(err, "Fail to put report chain")
+	}
+
+	return nil
+}
+
+func (x *DynamoRepository) getItem(pk, sk string, out interface{}) error {
+	resp, err := x.client.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(x.table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"PKey": {S: aws.String(pk)},
+			"SKey": {S: aws.String(sk)},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "Fail to get DynamoDB item")
+	}
+
+	if err := dynamodbattribute.UnmarshalMap(resp.Item, out); err != nil {
+		return errors.Wrap(err, "Fail to unmarshal DynamoDB item")
+	}
+	return nil
+}
+
+func (x *DynamoRepository) queryByPKey(pk string, out interface{}) error {
+	resp, err := x.client.Query(&dynamodb.QueryInput{
+		TableName:              aws.String(x.table),
+		KeyConditionExpression: aws.String("PKey = :pk"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk": {S: aws.String(pk)},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "Fail to query DynamoDB table")
+	}
+
+	if err := dynamodbattribute.UnmarshalListOfMaps(resp.Items, out); err != nil {
+		return errors.Wrap(err, "Fail to unmarshal DynamoDB items")
+	}
+	return nil
+}
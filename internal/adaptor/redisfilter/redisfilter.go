@@ -0,0 +1,89 @@
+// Package redisfilter implements service.FilterStore on top of Redis, so
+// the per-report attribute cuckoo filter can be shared across many
+// inspector instances (e.g. behind ElastiCache) instead of living in
+// whichever DynamoDB/SQL table backs the rest of RepositoryService.
+package redisfilter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/m-mizutani/deepalert"
+	"github.com/m-mizutani/deepalert/internal/adaptor"
+	"github.com/m-mizutani/deepalert/internal/errors"
+	"github.com/m-mizutani/deepalert/internal/models"
+)
+
+// casScript atomically updates a filter hash only if its current version
+// still matches ARGV[3] (0 meaning "must not exist yet"), mirroring the
+// conditional-put semantics every other Repository backend offers.
+var casScript = redis.NewScript(`
+local current = redis.call("HGET", KEYS[1], "version")
+if (current == false and ARGV[3] == "0") or (current == ARGV[3]) then
+	redis.call("HSET", KEYS[1], "data", ARGV[1], "version", ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// Store implements service.FilterStore against a Redis client.
+type Store struct {
+	client *redis.Client
+}
+
+// New wraps an existing Redis client. The caller owns the client's
+// lifecycle (including Close).
+func New(client *redis.Client) *Store {
+	return &Store{client: client}
+}
+
+func key(pkey string) string {
+	return "deepalert:" + pkey
+}
+
+func pkeyOf(reportID deepalert.ReportID) string {
+	return fmt.Sprintf("attrfilter/%s", reportID)
+}
+
+// Get returns the filter for reportID and its CAS version, or a nil
+// filter and version 0 if none has been stored yet. It satisfies
+// service.FilterStore.
+func (x *Store) Get(reportID deepalert.ReportID) (*models.AttributeFilter, int, error) {
+	ctx := context.Background()
+	pkey := pkeyOf(reportID)
+
+	values, err := x.client.HMGet(ctx, key(pkey), "data", "version").Result()
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "Fail to read attribute filter from redis")
+	}
+	if values[0] == nil {
+		return nil, 0, nil
+	}
+
+	data, _ := values[0].(string)
+	var version int
+	if v, ok := values[1].(string); ok {
+		fmt.Sscanf(v, "%d", &version)
+	}
+
+	return &models.AttributeFilter{RecordBase: models.RecordBase{PKey: pkey}, Data: []byte(data), Version: version}, version, nil
+}
+
+// Put stores filter, requiring the existing record's version to equal
+// expectedVersion. It returns adaptor.ErrVersionConflict if another
+// writer won the race. It satisfies service.FilterStore.
+func (x *Store) Put(filter *models.AttributeFilter, expectedVersion int) error {
+	ctx := context.Background()
+
+	ok, err := casScript.Run(ctx, x.client, []string{key(filter.PKey)},
+		string(filter.Data), filter.Version, expectedVersion).Int()
+	if err != nil {
+		return errors.Wrap(err, "Fail to run attribute filter CAS script")
+	}
+	if ok == 0 {
+		return adaptor.ErrVersionConflict
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package sqlrepo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PostgresDialect targets PostgreSQL.
+type PostgresDialect struct{}
+
+// Name implements Dialect.
+func (PostgresDialect) Name() string { return "postgres" }
+
+// InsertIgnore implements Dialect using ON CONFLICT DO NOTHING, relying on
+// each table's primary key ((pkey, skey), or pkey alone for
+// attribute_filters and report_chains).
+func (PostgresDialect) InsertIgnore(table string, columns []string) string {
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT DO NOTHING",
+		table, strings.Join(columns, ", "), placeholders(len(columns), "$"))
+}
+
+// MySQLDialect targets MySQL/MariaDB.
+type MySQLDialect struct{}
+
+// Name implements Dialect.
+func (MySQLDialect) Name() string { return "mysql" }
+
+// InsertIgnore implements Dialect using INSERT IGNORE, relying on a unique
+// index over (pkey, skey) for each swept table.
+func (MySQLDialect) InsertIgnore(table string, columns []string) string {
+	return fmt.Sprintf("INSERT IGNORE INTO %s (%s) VALUES (%s)",
+		table, strings.Join(columns, ", "), placeholders(len(columns), "?"))
+}
+
+// SQLiteDialect targets SQLite.
+type SQLiteDialect struct{}
+
+// Name implements Dialect.
+func (SQLiteDialect) Name() string { return "sqlite" }
+
+// InsertIgnore implements Dialect using INSERT OR IGNORE, relying on a
+// unique index over (pkey, skey) for each swept table.
+func (SQLiteDialect) InsertIgnore(table string, columns []string) string {
+	return fmt.Sprintf("INSERT OR IGNORE INTO %s (%s) VALUES (%s)",
+		table, strings.Join(columns, ", "), placeholders(len(columns), "?"))
+}
+
+func placeholders(n int, style string) string {
+	parts := make([]string, n)
+	for i := range parts {
+		if style == "$" {
+			parts[i] = fmt.Sprintf("$%d", i+1)
+		} else {
+			parts[i] = "?"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
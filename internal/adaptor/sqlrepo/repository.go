@@ -0,0 +1,251 @@
+package sqlrepo
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/m-mizutani/deepalert"
+	"github.com/m-mizutani/deepalert/internal/adaptor"
+	"github.com/m-mizutani/deepalert/internal/errors"
+	"github.com/m-mizutani/deepalert/internal/models"
+)
+
+// PutAlertEntry inserts entry, or returns adaptor.ErrAlreadyExists if a
+// row with the same (pkey, skey) already exists.
+func (x *Repository) PutAlertEntry(entry *models.AlertEntry, now time.Time) error {
+	stmt := x.dialect.InsertIgnore("alert_entries", []string{"pkey", "skey", "report_id", "expires_at", "created_at"})
+
+	res, err := x.db.Exec(stmt, entry.PKey, entry.SKey, string(entry.ReportID), entry.ExpiresAt, entry.CreatedAt)
+	if err != nil {
+		return errors.Wrap(err, "Fail to insert alert_entries")
+	}
+
+	return requireInserted(res, "alert_entries")
+}
+
+// GetAlertEntry fetches the AlertEntry stored under (pk, sk).
+func (x *Repository) GetAlertEntry(pk, sk string) (*models.AlertEntry, error) {
+	row := x.db.QueryRow("SELECT pkey, skey, report_id, expires_at, created_at FROM alert_entries WHERE pkey = ? AND skey = ?", pk, sk)
+
+	var entry models.AlertEntry
+	var reportID string
+	if err := row.Scan(&entry.PKey, &entry.SKey, &reportID, &entry.ExpiresAt, &entry.CreatedAt); err != nil {
+		return nil, errors.Wrap(err, "Fail to scan alert_entries")
+	}
+	entry.ReportID = deepalert.ReportID(reportID)
+
+	return &entry, nil
+}
+
+// PutAlertCache inserts cache. Alert caches are append-only (a report can
+// have many alerts), so there is no conflict to handle.
+func (x *Repository) PutAlertCache(cache *models.AlertCache) error {
+	_, err := x.db.Exec("INSERT INTO alert_caches (pkey, skey, alert_data, expires_at) VALUES (?, ?, ?, ?)",
+		cache.PKey, cache.SKey, cache.AlertData, cache.ExpiresAt)
+	if err != nil {
+		return errors.Wrap(err, "Fail to insert alert_caches")
+	}
+
+	return nil
+}
+
+// GetAlertCaches fetches every AlertCache stored under pk.
+func (x *Repository) GetAlertCaches(pk string) ([]*models.AlertCache, error) {
+	rows, err := x.db.Query("SELECT pkey, skey, alert_data, expires_at FROM alert_caches WHERE pkey = ?", pk)
+	if err != nil {
+		return nil, errors.Wrap(err, "Fail to query alert_caches")
+	}
+	defer rows.Close()
+
+	var caches []*models.AlertCache
+	for rows.Next() {
+		var cache models.AlertCache
+		if err := rows.Scan(&cache.PKey, &cache.SKey, &cache.AlertData, &cache.ExpiresAt); err != nil {
+			return nil, errors.Wrap(err, "Fail to scan alert_caches")
+		}
+		caches = append(caches, &cache)
+	}
+
+	return caches, rows.Err()
+}
+
+// PutReportSectionRecord inserts record, or returns adaptor.ErrAlreadyExists
+// if a row with the same (pkey, skey) already exists. SKey is derived from
+// the section's content hash (see toReportSectionRecord in
+// internal/service), so a retried write of an already-saved section is
+// rejected as a duplicate instead of stored twice.
+func (x *Repository) PutReportSectionRecord(record *models.ReportSectionRecord) error {
+	stmt := x.dialect.InsertIgnore("report_sections", []string{"pkey", "skey", "data", "section_hash", "seq", "expires_at", "created_at"})
+
+	res, err := x.db.Exec(stmt, record.PKey, record.SKey, record.Data, record.SectionHash, record.Seq, record.ExpiresAt, record.CreatedAt)
+	if err != nil {
+		return errors.Wrap(err, "Fail to insert report_sections")
+	}
+
+	return requireInserted(res, "report_sections")
+}
+
+// GetReportSection fetches every ReportSectionRecord stored under pk, in
+// no particular order - callers that need chain order should sort by Seq
+// (see RepositoryService.FetchChainedSections) rather than relying on
+// this query's order.
+func (x *Repository) GetReportSection(pk string) ([]*models.ReportSectionRecord, error) {
+	rows, err := x.db.Query("SELECT pkey, skey, data, section_hash, seq, expires_at, created_at FROM report_sections WHERE pkey = ?", pk)
+	if err != nil {
+		return nil, errors.Wrap(err, "Fail to query report_sections")
+	}
+	defer rows.Close()
+
+	var records []*models.ReportSectionRecord
+	for rows.Next() {
+		var record models.ReportSectionRecord
+		if err := rows.Scan(&record.PKey, &record.SKey, &record.Data, &record.SectionHash, &record.Seq, &record.ExpiresAt, &record.CreatedAt); err != nil {
+			return nil, errors.Wrap(err, "Fail to scan report_sections")
+		}
+		records = append(records, &record)
+	}
+
+	return records, rows.Err()
+}
+
+// PutAttributeCache inserts cache, or returns adaptor.ErrAlreadyExists if
+// this attribute was already cached for the report.
+func (x *Repository) PutAttributeCache(cache *models.AttributeCache, now time.Time) error {
+	stmt := x.dialect.InsertIgnore("attribute_caches",
+		[]string{"pkey", "skey", "attr_key", "attr_type", "attr_value", "timestamp", "expires_at"})
+
+	res, err := x.db.Exec(stmt, cache.PKey, cache.SKey, cache.AttrKey, cache.AttrType, cache.AttrValue, cache.Timestamp, cache.ExpiresAt)
+	if err != nil {
+		return errors.Wrap(err, "Fail to insert attribute_caches")
+	}
+
+	return requireInserted(res, "attribute_caches")
+}
+
+// GetAttributeCaches fetches every AttributeCache stored under pk.
+func (x *Repository) GetAttributeCaches(pk string) ([]*models.AttributeCache, error) {
+	rows, err := x.db.Query("SELECT pkey, skey, attr_key, attr_type, attr_value, timestamp, expires_at FROM attribute_caches WHERE pkey = ?", pk)
+	if err != nil {
+		return nil, errors.Wrap(err, "Fail to query attribute_caches")
+	}
+	defer rows.Close()
+
+	var caches []*models.AttributeCache
+	for rows.Next() {
+		var cache models.AttributeCache
+		if err := rows.Scan(&cache.PKey, &cache.SKey, &cache.AttrKey, &cache.AttrType, &cache.AttrValue, &cache.Timestamp, &cache.ExpiresAt); err != nil {
+			return nil, errors.Wrap(err, "Fail to scan attribute_caches")
+		}
+		caches = append(caches, &cache)
+	}
+
+	return caches, rows.Err()
+}
+
+func attrFilterKey(reportID deepalert.ReportID) string {
+	return "attrfilter/" + string(reportID)
+}
+
+// GetAttributeFilter implements Repository.
+func (x *Repository) GetAttributeFilter(reportID deepalert.ReportID) (*models.AttributeFilter, error) {
+	row := x.db.QueryRow("SELECT data, version, saturated FROM attribute_filters WHERE pkey = ?", attrFilterKey(reportID))
+
+	var filter models.AttributeFilter
+	if err := row.Scan(&filter.Data, &filter.Version, &filter.Saturated); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "Fail to scan attribute_filters")
+	}
+	filter.PKey = attrFilterKey(reportID)
+
+	return &filter, nil
+}
+
+// PutAttributeFilter implements Repository.
+func (x *Repository) PutAttributeFilter(filter *models.AttributeFilter, expectedVersion int) error {
+	pk := filter.PKey
+
+	var res sql.Result
+	var err error
+	if expectedVersion == 0 {
+		res, err = x.db.Exec(x.dialect.InsertIgnore("attribute_filters", []string{"pkey", "data", "version", "saturated"}),
+			pk, filter.Data, filter.Version, filter.Saturated)
+	} else {
+		res, err = x.db.Exec("UPDATE attribute_filters SET data = ?, version = ?, saturated = ? WHERE pkey = ? AND version = ?",
+			filter.Data, filter.Version, filter.Saturated, pk, expectedVersion)
+	}
+	if err != nil {
+		return errors.Wrap(err, "Fail to upsert attribute_filters")
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "Fail to read rows affected for attribute_filters")
+	}
+	if n == 0 {
+		return adaptor.ErrVersionConflict
+	}
+
+	return nil
+}
+
+func reportChainKey(reportID deepalert.ReportID) string {
+	return "reportchain/" + string(reportID)
+}
+
+// GetReportChain implements Repository.
+func (x *Repository) GetReportChain(reportID deepalert.ReportID) (*models.ReportChain, error) {
+	row := x.db.QueryRow("SELECT prev_hash, version FROM report_chains WHERE pkey = ?", reportChainKey(reportID))
+
+	var chain models.ReportChain
+	if err := row.Scan(&chain.PrevHash, &chain.Version); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "Fail to scan report_chains")
+	}
+	chain.PKey = reportChainKey(reportID)
+
+	return &chain, nil
+}
+
+// PutReportChain implements Repository.
+func (x *Repository) PutReportChain(chain *models.ReportChain, expectedVersion int) error {
+	var res sql.Result
+	var err error
+	if expectedVersion == 0 {
+		res, err = x.db.Exec(x.dialect.InsertIgnore("report_chains", []string{"pkey", "prev_hash", "version"}),
+			chain.PKey, chain.PrevHash, chain.Version)
+	} else {
+		res, err = x.db.Exec("UPDATE report_chains SET prev_hash = ?, version = ? WHERE pkey = ? AND version = ?",
+			chain.PrevHash, chain.Version, chain.PKey, expectedVersion)
+	}
+	if err != nil {
+		return errors.Wrap(err, "Fail to upsert report_chains")
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "Fail to read rows affected for report_chains")
+	}
+	if n == 0 {
+		return adaptor.ErrVersionConflict
+	}
+
+	return nil
+}
+
+// requireInserted turns a zero-rows-affected InsertIgnore result into
+// adaptor.ErrAlreadyExists, matching the conditional-put semantics
+// RepositoryService expects from PutAlertEntry and PutAttributeCache.
+func requireInserted(res sql.Result, table string) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrapf(err, "Fail to read rows affected for %s", table)
+	}
+	if n == 0 {
+		return adaptor.ErrAlreadyExists
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Package sqlrepo implements adaptor.Repository on top of database/sql,
+// letting DeepAlert run against Postgres, MySQL or SQLite instead of
+// DynamoDB for on-prem deployments. Unlike DynamoDB, SQL has no native
+// per-item TTL, so expired rows are swept by a background goroutine
+// instead of expiring on their own.
+package sqlrepo
+
+import (
+	"database/sql"
+	"time"
+)
+
+var sweptTables = []string{"alert_entries", "alert_caches", "report_sections", "attribute_caches"}
+
+// Dialect abstracts the handful of SQL differences between backends: how
+// to upsert-if-not-exists for each supported database.
+type Dialect interface {
+	// Name identifies the dialect, e.g. for logging.
+	Name() string
+	// InsertIgnore returns an INSERT statement for table (with the given
+	// columns, in order) that reports no rows affected instead of erroring
+	// when a row with the same primary key already exists.
+	InsertIgnore(table string, columns []string) string
+}
+
+// Repository implements adaptor.Repository against a SQL database.
+type Repository struct {
+	db      *sql.DB
+	dialect Dialect
+
+	stopSweep chan struct{}
+}
+
+// New opens a Repository against db using dialect, and starts a
+// background goroutine that sweeps expired rows every sweepInterval.
+// Callers must call Close to stop the sweeper when done with the
+// Repository.
+func New(db *sql.DB, dialect Dialect, sweepInterval time.Duration) *Repository {
+	x := &Repository{
+		db:        db,
+		dialect:   dialect,
+		stopSweep: make(chan struct{}),
+	}
+
+	go x.sweepLoop(sweepInterval)
+
+	return x
+}
+
+// Close stops the TTL sweeper. It does not close the underlying *sql.DB,
+// which the caller owns.
+func (x *Repository) Close() {
+	close(x.stopSweep)
+}
+
+func (x *Repository) sweepLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			x.sweepExpired(time.Now())
+		case <-x.stopSweep:
+			return
+		}
+	}
+}
+
+func (x *Repository) sweepExpired(now time.Time) {
+	for _, table := range sweptTables {
+		// Best-effort: a failed sweep just leaves rows to be picked up on
+		// the next tick, it never blocks request-serving code.
+		_, _ = x.db.Exec("DELETE FROM "+table+" WHERE expires_at < ?", now.Unix())
+	}
+}
+
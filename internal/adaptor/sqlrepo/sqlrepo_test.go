@@ -0,0 +1,36 @@
+package sqlrepo_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/m-mizutani/deepalert/internal/adaptor"
+	"github.com/m-mizutani/deepalert/internal/adaptor/adaptortest"
+	"github.com/m-mizutani/deepalert/internal/adaptor/sqlrepo"
+	"github.com/stretchr/testify/require"
+)
+
+const schema = `
+CREATE TABLE alert_entries (pkey TEXT, skey TEXT, report_id TEXT, expires_at INTEGER, created_at DATETIME, PRIMARY KEY (pkey, skey));
+CREATE TABLE alert_caches (pkey TEXT, skey TEXT, alert_data BLOB, expires_at INTEGER, PRIMARY KEY (pkey, skey));
+CREATE TABLE report_sections (pkey TEXT, skey TEXT, data BLOB, section_hash TEXT, seq INTEGER, expires_at INTEGER, created_at DATETIME, PRIMARY KEY (pkey, skey));
+CREATE TABLE attribute_caches (pkey TEXT, skey TEXT, attr_key TEXT, attr_type TEXT, attr_value TEXT, timestamp DATETIME, expires_at INTEGER, PRIMARY KEY (pkey, skey));
+CREATE TABLE attribute_filters (pkey TEXT PRIMARY KEY, data BLOB, version INTEGER, saturated BOOLEAN);
+CREATE TABLE report_chains (pkey TEXT PRIMARY KEY, prev_hash TEXT, version INTEGER);
+`
+
+func TestSQLRepoConformance(t *testing.T) {
+	adaptortest.RunConformanceTests(t, func(t *testing.T) adaptor.Repository {
+		db, err := sql.Open("sqlite3", ":memory:")
+		require.NoError(t, err)
+		_, err = db.Exec(schema)
+		require.NoError(t, err)
+
+		repo := sqlrepo.New(db, sqlrepo.SQLiteDialect{}, time.Hour)
+		t.Cleanup(repo.Close)
+
+		return repo
+	})
+}
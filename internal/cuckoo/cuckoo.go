@@ -0,0 +1,170 @@
+// Package cuckoo implements a small fixed-capacity cuckoo filter used to
+// front RepositoryService.PutAttributeCache: testing set membership in a
+// ~32KB in-memory filter is far cheaper than a DynamoDB conditional put,
+// and the filter itself serializes small enough to live in a single item.
+package cuckoo
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math/rand"
+)
+
+const (
+	bucketSize      = 4
+	maxKicks        = 500
+	emptySlot       = uint16(0)
+	fingerprintMask = 0xFFFF
+)
+
+// Filter is a fixed-size cuckoo filter storing 16-bit fingerprints in
+// buckets of 4. Capacity is numBuckets * bucketSize entries; once Insert
+// cannot make room for an item within maxKicks relocations it fails, and
+// the caller should treat the filter as saturated.
+type Filter struct {
+	buckets [][bucketSize]uint16
+}
+
+// New creates an empty Filter sized to hold at least capacity entries.
+func New(capacity int) *Filter {
+	numBuckets := nextPowerOfTwo((capacity + bucketSize - 1) / bucketSize)
+	if numBuckets < 1 {
+		numBuckets = 1
+	}
+	return &Filter{buckets: make([][bucketSize]uint16, numBuckets)}
+}
+
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p *= 2
+	}
+	return p
+}
+
+func fingerprintOf(h uint64) uint16 {
+	fp := uint16(h & fingerprintMask)
+	if fp == emptySlot {
+		fp = 1
+	}
+	return fp
+}
+
+func (f *Filter) indices(key string) (int, int, uint16) {
+	h := fnv1a64(key)
+	fp := fingerprintOf(h)
+	i1 := int(h>>32) % len(f.buckets)
+	i2 := (i1 ^ int(fnv1a64String(fp))) % len(f.buckets)
+	if i2 < 0 {
+		i2 += len(f.buckets)
+	}
+	return i1, i2, fp
+}
+
+// Contains reports whether key may have been inserted. Like all
+// probabilistic filters it can false-positive. It does not false-negative
+// unless an Insert has failed: a failed Insert leaves the last fingerprint
+// it evicted out of the filter.
+func (f *Filter) Contains(key string) bool {
+	i1, i2, fp := f.indices(key)
+	return f.bucketHas(i1, fp) || f.bucketHas(i2, fp)
+}
+
+func (f *Filter) bucketHas(i int, fp uint16) bool {
+	for _, slot := range f.buckets[i] {
+		if slot == fp {
+			return true
+		}
+	}
+	return false
+}
+
+// Insert adds key to the filter, returning false if every candidate
+// bucket is full after maxKicks relocation attempts (the filter is
+// saturated and the caller should fall back to the authoritative store).
+func (f *Filter) Insert(key string) bool {
+	i1, i2, fp := f.indices(key)
+
+	if f.insertInto(i1, fp) || f.insertInto(i2, fp) {
+		return true
+	}
+
+	i := i1
+	if rand.Intn(2) == 1 {
+		i = i2
+	}
+
+	for n := 0; n < maxKicks; n++ {
+		slot := rand.Intn(bucketSize)
+		fp, f.buckets[i][slot] = f.buckets[i][slot], fp
+
+		i = (i ^ int(fnv1a64String(fp))) % len(f.buckets)
+		if i < 0 {
+			i += len(f.buckets)
+		}
+
+		if f.insertInto(i, fp) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *Filter) insertInto(i int, fp uint16) bool {
+	for j, slot := range f.buckets[i] {
+		if slot == emptySlot {
+			f.buckets[i][j] = fp
+			return true
+		}
+	}
+	return false
+}
+
+// Marshal serializes the filter to a flat byte slice for storage as a
+// single DynamoDB/SQL blob.
+func (f *Filter) Marshal() []byte {
+	out := make([]byte, 4+len(f.buckets)*bucketSize*2)
+	binary.BigEndian.PutUint32(out, uint32(len(f.buckets)))
+	offset := 4
+	for _, bucket := range f.buckets {
+		for _, fp := range bucket {
+			binary.BigEndian.PutUint16(out[offset:], fp)
+			offset += 2
+		}
+	}
+	return out
+}
+
+// Unmarshal restores a Filter previously produced by Marshal.
+func Unmarshal(data []byte) *Filter {
+	if len(data) < 4 {
+		return New(0)
+	}
+	numBuckets := int(binary.BigEndian.Uint32(data))
+	f := &Filter{buckets: make([][bucketSize]uint16, numBuckets)}
+
+	offset := 4
+	for i := range f.buckets {
+		for j := range f.buckets[i] {
+			if offset+2 > len(data) {
+				return f
+			}
+			f.buckets[i][j] = binary.BigEndian.Uint16(data[offset:])
+			offset += 2
+		}
+	}
+	return f
+}
+
+func fnv1a64(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum64()
+}
+
+func fnv1a64String(fp uint16) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte{byte(fp >> 8), byte(fp)})
+	return h.Sum64()
+}
@@ -0,0 +1,43 @@
+package cuckoo_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/m-mizutani/deepalert/internal/cuckoo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterInsertAndContains(t *testing.T) {
+	f := cuckoo.New(1000)
+
+	require.True(t, f.Insert("10.0.0.1"))
+	assert.True(t, f.Contains("10.0.0.1"))
+	assert.False(t, f.Contains("10.0.0.2"))
+}
+
+func TestFilterMarshalRoundTrip(t *testing.T) {
+	f := cuckoo.New(1000)
+	require.True(t, f.Insert("example.com"))
+
+	restored := cuckoo.Unmarshal(f.Marshal())
+	assert.True(t, restored.Contains("example.com"))
+	assert.False(t, restored.Contains("not-inserted.example.com"))
+}
+
+func TestFilterSaturatesGracefully(t *testing.T) {
+	f := cuckoo.New(16)
+
+	inserted := 0
+	for i := 0; i < 1000; i++ {
+		if f.Insert(fmt.Sprintf("item-%d", i)) {
+			inserted++
+		} else {
+			break
+		}
+	}
+
+	assert.Greater(t, inserted, 0)
+	assert.Less(t, inserted, 1000)
+}
@@ -0,0 +1,111 @@
+package errors
+
+import "fmt"
+
+// Error wraps an underlying error with structured context key/value pairs
+// so handlers can attach fields (ReportID, AlertID, ...) at the point of
+// failure and have them flow through to logging without every call site
+// building its own log.Fields.
+type Error struct {
+	msg     string
+	cause   error
+	Kind    Kind
+	Context map[string]interface{}
+}
+
+// Kind classifies an Error so callers like handler.StartLambda can decide
+// whether to retry or to dead-letter a failure without inspecting message
+// strings.
+type Kind string
+
+const (
+	// KindUnclassified is the zero value: treated as retryable, matching
+	// the historical behavior of returning any error to Lambda.
+	KindUnclassified Kind = ""
+	// KindTransient marks a failure expected to succeed on retry (e.g. a
+	// network blip).
+	KindTransient Kind = "transient"
+	// KindPermanent marks a failure that will never succeed on retry (e.g.
+	// a programming error or a record that can never be processed).
+	KindPermanent Kind = "permanent"
+	// KindInvalidInput marks a failure caused by malformed input, which
+	// retrying will not fix.
+	KindInvalidInput Kind = "invalid_input"
+	// KindThrottled marks a failure caused by hitting a rate limit, which
+	// is expected to succeed on retry after backoff.
+	KindThrottled Kind = "throttled"
+)
+
+// IsRetryable reports whether Lambda should retry the invocation that
+// produced this error, as opposed to sending it to a dead-letter queue.
+func (k Kind) IsRetryable() bool {
+	return k == KindUnclassified || k == KindTransient || k == KindThrottled
+}
+
+// New creates a bare *Error with no underlying cause.
+func New(msg string) *Error {
+	return &Error{msg: msg, Context: map[string]interface{}{}}
+}
+
+// ErrTransient creates an *Error classified as KindTransient.
+func ErrTransient(msg string) *Error {
+	return New(msg).WithKind(KindTransient)
+}
+
+// ErrPermanent creates an *Error classified as KindPermanent.
+func ErrPermanent(msg string) *Error {
+	return New(msg).WithKind(KindPermanent)
+}
+
+// ErrInvalidInput creates an *Error classified as KindInvalidInput.
+func ErrInvalidInput(msg string) *Error {
+	return New(msg).WithKind(KindInvalidInput)
+}
+
+// ErrThrottled creates an *Error classified as KindThrottled.
+func ErrThrottled(msg string) *Error {
+	return New(msg).WithKind(KindThrottled)
+}
+
+// Wrap attaches msg to cause, preserving cause for errors.Unwrap. Callers
+// must only call it once cause is known to be non-nil (e.g. inside an
+// `if err != nil` block): a nil *Error returned directly as an error
+// interface value is non-nil, the classic Go typed-nil trap.
+func Wrap(cause error, msg string) *Error {
+	return &Error{msg: msg, cause: cause, Context: map[string]interface{}{}}
+}
+
+// Wrapf is Wrap with a formatted message.
+func Wrapf(cause error, format string, args ...interface{}) *Error {
+	return Wrap(cause, fmt.Sprintf(format, args...))
+}
+
+func (x *Error) Error() string {
+	if x.cause != nil {
+		return fmt.Sprintf("%s: %v", x.msg, x.cause)
+	}
+	return x.msg
+}
+
+// Unwrap allows errors.Is/As to see through to the original cause.
+func (x *Error) Unwrap() error {
+	return x.cause
+}
+
+// With attaches a key/value pair to the error's Context and returns the
+// same *Error so calls can be chained.
+func (x *Error) With(key string, value interface{}) *Error {
+	x.Context[key] = value
+	return x
+}
+
+// WithKind sets the error's Kind and returns the same *Error so calls can
+// be chained.
+func (x *Error) WithKind(kind Kind) *Error {
+	x.Kind = kind
+	return x
+}
+
+// Flush is a hook point for deferred cleanup (e.g. flushing buffered error
+// reporting) at the end of a Lambda invocation. It is a no-op today.
+func Flush() {}
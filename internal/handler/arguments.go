@@ -0,0 +1,43 @@
+package handler
+
+import "os"
+
+// Arguments carries configuration and per-invocation state for a Lambda
+// handler function, populated from environment variables via BindEnvVars
+// and from the inbound Lambda event.
+type Arguments struct {
+	LogLevel string
+	Event    interface{}
+
+	// DLQueueURL is the SQS queue that permanent and invalid-input
+	// failures are sent to instead of being retried by Lambda.
+	DLQueueURL string
+
+	// RepoBackend selects the adaptor.Repository implementation:
+	// "dynamo" (default), "postgres", "mysql" or "sqlite".
+	RepoBackend string
+	// RepoDSN is the connection string for RepoBackend when it is a SQL
+	// backend; unused for "dynamo".
+	RepoDSN string
+	// RepoTable is the DynamoDB table name; unused for SQL backends.
+	RepoTable string
+}
+
+func newArguments() *Arguments {
+	return &Arguments{}
+}
+
+// BindEnvVars populates Arguments from environment variables.
+func (x *Arguments) BindEnvVars() error {
+	x.LogLevel = os.Getenv("LOG_LEVEL")
+	x.DLQueueURL = os.Getenv("DLQ_URL")
+
+	x.RepoBackend = os.Getenv("DEEPALERT_REPO")
+	if x.RepoBackend == "" {
+		x.RepoBackend = "dynamo"
+	}
+	x.RepoDSN = os.Getenv("DEEPALERT_REPO_DSN")
+	x.RepoTable = os.Getenv("DEEPALERT_REPO_TABLE")
+
+	return nil
+}
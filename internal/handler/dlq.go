@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/m-mizutani/deepalert/internal/errors"
+)
+
+type sqsClient interface {
+	SendMessage(*sqs.SendMessageInput) (*sqs.SendMessageOutput, error)
+}
+
+var newSQSClient = func() sqsClient {
+	return sqs.New(session.New())
+}
+
+// deadLetterMessage is the payload sent to Arguments.DLQueueURL for a
+// failure that Lambda should not retry, carrying the original event and
+// whatever context the failing handler attached to its error.
+type deadLetterMessage struct {
+	Event   interface{}            `json:"event"`
+	Error   string                 `json:"error"`
+	Kind    errors.Kind            `json:"kind"`
+	Context map[string]interface{} `json:"context,omitempty"`
+}
+
+func sendToDLQ(queueURL string, event interface{}, err error) error {
+	msg := deadLetterMessage{
+		Event: event,
+		Error: err.Error(),
+	}
+
+	if daErr, ok := err.(*errors.Error); ok {
+		msg.Kind = daErr.Kind
+		msg.Context = daErr.Context
+	}
+
+	raw, marshalErr := json.Marshal(msg)
+	if marshalErr != nil {
+		return errors.Wrap(marshalErr, "Fail to marshal dead-letter message")
+	}
+
+	_, sendErr := newSQSClient().SendMessage(&sqs.SendMessageInput{
+		QueueUrl:    aws.String(queueURL),
+		MessageBody: aws.String(string(raw)),
+	})
+	if sendErr != nil {
+		return errors.Wrap(sendErr, "Fail to send dead-letter message").With("queueURL", queueURL)
+	}
+
+	return nil
+}
@@ -42,19 +42,63 @@ func StartLambda(handler Handler) {
 
 		out, err := handler(args)
 		if err != nil {
-			fields := logrus.Fields{
-				"args":  args,
-				"event": event,
-				"error": err,
-			}
-
-			if daErr, ok := err.(*errors.Error); ok {
-				fields["context"] = daErr.Context
-			}
-			Logger.WithFields(fields).Error("Failed Handler")
-			return nil, err
+			return nil, handleFailure(args, event, err)
 		}
 
 		return out, nil
 	})
+}
+
+// handleFailure logs a structured failure record and decides whether
+// Lambda should retry the invocation. Permanent and invalid-input errors
+// are drained to args.DLQueueURL and swallowed (returning nil) so Lambda
+// stops retrying; everything else, including unclassified errors, is
+// returned as-is so Lambda retries it.
+func handleFailure(args *Arguments, event interface{}, err error) error {
+	return ClassifyFailure(args.DLQueueURL, event, err)
+}
+
+// ClassifyFailure logs a structured failure record for err (with event as
+// context) and decides whether the caller's Lambda transport should retry
+// the invocation. Permanent and invalid-input errors are drained to
+// dlQueueURL and swallowed (returning nil) so Lambda stops retrying;
+// everything else, including unclassified errors, is returned as-is so
+// Lambda retries it. It is exported so transports outside this package,
+// such as inspector.Start, get the same taxonomy/DLQ behavior as
+// StartLambda instead of reimplementing it.
+func ClassifyFailure(dlQueueURL string, event interface{}, err error) error {
+	fields := logrus.Fields{
+		"event": event,
+		"error": err.Error(),
+	}
+
+	var kind errors.Kind
+	if daErr, ok := err.(*errors.Error); ok {
+		kind = daErr.Kind
+		fields["kind"] = daErr.Kind
+		fields["context"] = daErr.Context
+		for _, key := range []string{"ReportID", "AlertID", "AttributeHash"} {
+			if v, ok := daErr.Context[key]; ok {
+				fields[key] = v
+			}
+		}
+	}
+
+	Logger.WithFields(fields).Error("Failed Handler")
+
+	if kind.IsRetryable() {
+		return err
+	}
+
+	if dlQueueURL == "" {
+		Logger.WithFields(fields).Warn("No DLQueueURL configured, dropping non-retryable failure")
+		return nil
+	}
+
+	if dlqErr := sendToDLQ(dlQueueURL, event, err); dlqErr != nil {
+		Logger.WithFields(fields).WithError(dlqErr).Error("Failed to send to DLQ")
+		return dlqErr
+	}
+
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"database/sql"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/m-mizutani/deepalert/internal/adaptor"
+	"github.com/m-mizutani/deepalert/internal/adaptor/sqlrepo"
+	"github.com/m-mizutani/deepalert/internal/errors"
+)
+
+// sqlSweepInterval is how often sqlrepo backends sweep expired rows,
+// since SQL (unlike DynamoDB) has no native per-item TTL.
+const sqlSweepInterval = 10 * time.Minute
+
+// NewRepository builds the adaptor.Repository selected by
+// args.RepoBackend ("dynamo", "postgres", "mysql" or "sqlite"), so an
+// on-prem deployment can point DEEPALERT_REPO at a SQL database instead
+// of requiring DynamoDB. The returned close func must be called once the
+// repository is no longer needed: sqlrepo backends start a background TTL
+// sweeper goroutine that only close stops (dynamo's is a no-op).
+func NewRepository(args *Arguments) (adaptor.Repository, func() error, error) {
+	switch args.RepoBackend {
+	case "", "dynamo":
+		return adaptor.NewDynamoRepository(args.RepoTable), func() error { return nil }, nil
+	case "postgres":
+		return openSQLRepository("postgres", args.RepoDSN, sqlrepo.PostgresDialect{})
+	case "mysql":
+		return openSQLRepository("mysql", args.RepoDSN, sqlrepo.MySQLDialect{})
+	case "sqlite":
+		return openSQLRepository("sqlite3", args.RepoDSN, sqlrepo.SQLiteDialect{})
+	default:
+		return nil, nil, errors.ErrInvalidInput("Unknown RepoBackend").With("RepoBackend", args.RepoBackend)
+	}
+}
+
+func openSQLRepository(driverName, dsn string, dialect sqlrepo.Dialect) (adaptor.Repository, func() error, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Fail to open %s database", driverName)
+	}
+
+	repo := sqlrepo.New(db, dialect, sqlSweepInterval)
+	return repo, func() error { repo.Close(); return nil }, nil
+}
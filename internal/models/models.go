@@ -0,0 +1,81 @@
+// Package models defines the records persisted by adaptor.Repository
+// implementations. They are intentionally storage-agnostic: DynamoDB maps
+// them onto PKey/SKey item keys, sqlrepo maps them onto table rows.
+package models
+
+import (
+	"time"
+
+	"github.com/m-mizutani/deepalert"
+)
+
+// RecordBase holds the fields common to every record kind: a partition
+// key, a sort key and a TTL expressed as a unix timestamp.
+type RecordBase struct {
+	PKey      string
+	SKey      string
+	ExpiresAt int64
+	CreatedAt time.Time
+}
+
+// AlertEntry maps an AlertID to the ReportID it was assigned.
+type AlertEntry struct {
+	RecordBase
+	ReportID deepalert.ReportID
+}
+
+// AlertCache holds a single raw alert as received, kept so a report can be
+// re-rendered with all of its contributing alerts.
+type AlertCache struct {
+	PKey      string
+	SKey      string
+	AlertData []byte
+	ExpiresAt int64
+}
+
+// ReportSectionRecord holds one inspector's contribution to a report.
+// SectionHash is sha256(Data), kept alongside the record so the report's
+// hash chain (see ReportChain) can be rebuilt and verified without
+// re-hashing every section on every read. Seq is the ReportChain version
+// this section was chained at, letting a reader reconstruct the exact
+// chain order (GetReportSection makes no ordering guarantee of its own).
+type ReportSectionRecord struct {
+	RecordBase
+	Data        []byte
+	SectionHash string
+	Seq         int
+}
+
+// ReportChain is the append-only hash chain over every ReportSectionRecord
+// saved for a report: PrevHash = H(PrevHash || sectionHash || author) (see
+// deepalert.ComputeChainLink); no timestamp is folded into the chain.
+// Version is used for compare-and-swap updates so concurrent
+// inspectors extending the same report's chain serialize correctly.
+type ReportChain struct {
+	RecordBase
+	PrevHash string
+	Version  int
+}
+
+// AttributeCache records that a given attribute has already been
+// dispatched to inspectors for a report, so it is not processed twice.
+type AttributeCache struct {
+	RecordBase
+	Timestamp   time.Time
+	AttrKey     string
+	AttrType    string
+	AttrValue   string
+	AttrContext interface{}
+}
+
+// AttributeFilter is the serialized cuckoo filter RepositoryService uses
+// to cheaply test "have we already cached this attribute for this
+// report?" before paying for a conditional write to AttributeCache.
+// Version is used for compare-and-swap updates so concurrent inspectors
+// updating the same report's filter serialize correctly.
+type AttributeFilter struct {
+	RecordBase
+	Data      []byte
+	Version   int
+	Saturated bool
+}
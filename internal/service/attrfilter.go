@@ -0,0 +1,122 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/m-mizutani/deepalert"
+	"github.com/m-mizutani/deepalert/internal/adaptor"
+	"github.com/m-mizutani/deepalert/internal/cuckoo"
+	"github.com/m-mizutani/deepalert/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// attributeFilterCapacity bounds the cuckoo filter so its serialized form
+// stays comfortably under DynamoDB's 400KB item limit: 10k entries rounds
+// up to 4096 buckets of 4 slots at 2 bytes each, serializing to about 32KB.
+const attributeFilterCapacity = 10000
+
+// FilterStore persists the per-report cuckoo filter blob that fronts
+// PutAttributeCache. It is a separate interface from adaptor.Repository
+// so the filter can live somewhere cheaper to hit repeatedly, such as
+// ElastiCache/Redis, without every Repository backend having to support
+// that.
+type FilterStore interface {
+	// Get returns the filter for reportID and its CAS version, or a nil
+	// filter and version 0 if none has been stored yet.
+	Get(reportID deepalert.ReportID) (filter *models.AttributeFilter, version int, err error)
+	// Put stores filter, requiring the existing record's version to
+	// equal expectedVersion (0 meaning "does not exist yet"). It returns
+	// adaptor.ErrVersionConflict if another writer won the race.
+	Put(filter *models.AttributeFilter, expectedVersion int) error
+}
+
+// repoFilterStore is the default FilterStore, backed by whatever
+// adaptor.Repository RepositoryService was built with.
+type repoFilterStore struct {
+	repo adaptor.Repository
+}
+
+func (x *repoFilterStore) Get(reportID deepalert.ReportID) (*models.AttributeFilter, int, error) {
+	filter, err := x.repo.GetAttributeFilter(reportID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if filter == nil {
+		return nil, 0, nil
+	}
+	return filter, filter.Version, nil
+}
+
+func (x *repoFilterStore) Put(filter *models.AttributeFilter, expectedVersion int) error {
+	return x.repo.PutAttributeFilter(filter, expectedVersion)
+}
+
+func attrFilterKey(reportID deepalert.ReportID) string {
+	return fmt.Sprintf("attrfilter/%s", reportID)
+}
+
+// testAndMarkAttributeSeen reports whether attr has already been cached
+// for reportID, consulting a cuckoo filter before falling back to
+// deepalert's normal conditional-put on AttributeCache. Any error loading
+// or saving the filter is non-fatal: it just means this attribute pays
+// the full conditional-put cost, same as before this optimization
+// existed.
+//
+// The filter's 16-bit fingerprints give it a false-positive rate on the
+// order of 1/65536 per probe, which a report carrying thousands of
+// attributes can compound into a real chance of wrongly reporting a never-
+// before-seen attribute as already cached. A Contains hit is therefore only
+// trusted to skip PutAttributeCache's authoritative conditional-put when
+// x.trustFilterHits has been explicitly opted into via SetTrustFilterHits;
+// by default a hit is just logged and this still returns false, so the
+// worst a false positive costs is one redundant conditional-put rather than
+// a silently dropped attribute.
+func (x *RepositoryService) testAndMarkAttributeSeen(reportID deepalert.ReportID, attr deepalert.Attribute, now time.Time) bool {
+	record, version, err := x.filter.Get(reportID)
+	if err != nil {
+		return false
+	}
+
+	var f *cuckoo.Filter
+	saturated := false
+	if record == nil {
+		f = cuckoo.New(attributeFilterCapacity)
+	} else {
+		saturated = record.Saturated
+		f = cuckoo.Unmarshal(record.Data)
+	}
+
+	key := attr.Hash()
+	if f.Contains(key) {
+		logrus.WithFields(logrus.Fields{
+			"reportID": reportID,
+			"attrHash": key,
+			"trusted":  x.trustFilterHits,
+		}).Debug("Cuckoo filter reported attribute as already seen")
+		return x.trustFilterHits
+	}
+	if saturated {
+		return false
+	}
+
+	inserted := f.Insert(key)
+
+	updated := &models.AttributeFilter{
+		RecordBase: models.RecordBase{
+			PKey:      attrFilterKey(reportID),
+			SKey:      "Fixed",
+			ExpiresAt: now.Add(x.ttl).Unix(),
+		},
+		Data:      f.Marshal(),
+		Version:   version + 1,
+		Saturated: !inserted,
+	}
+
+	// Best-effort: whether this CAS succeeds or loses a race to another
+	// inspector, the authoritative conditional-put on AttributeCache
+	// below is still the source of truth for "have we seen this attribute".
+	_ = x.filter.Put(updated, version)
+
+	return false
+}
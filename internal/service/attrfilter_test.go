@@ -0,0 +1,150 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/deepalert"
+	"github.com/m-mizutani/deepalert/internal/adaptor"
+	"github.com/m-mizutani/deepalert/internal/models"
+	"github.com/m-mizutani/deepalert/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memRepository is a minimal in-memory adaptor.Repository, enough to
+// exercise RepositoryService.PutAttributeCache without a real backend.
+type memRepository struct {
+	attrs    map[string]bool
+	filters  map[string]*models.AttributeFilter
+	chains   map[string]*models.ReportChain
+	sections map[string][]*models.ReportSectionRecord
+
+	putAttributeCacheCalls int
+}
+
+func newMemRepository() *memRepository {
+	return &memRepository{
+		attrs:    map[string]bool{},
+		filters:  map[string]*models.AttributeFilter{},
+		chains:   map[string]*models.ReportChain{},
+		sections: map[string][]*models.ReportSectionRecord{},
+	}
+}
+
+func (m *memRepository) PutAlertEntry(*models.AlertEntry, time.Time) error { return nil }
+func (m *memRepository) GetAlertEntry(string, string) (*models.AlertEntry, error) {
+	return nil, nil
+}
+func (m *memRepository) PutAlertCache(*models.AlertCache) error { return nil }
+func (m *memRepository) GetAlertCaches(string) ([]*models.AlertCache, error) {
+	return nil, nil
+}
+func (m *memRepository) PutReportSectionRecord(record *models.ReportSectionRecord) error {
+	for _, existing := range m.sections[record.PKey] {
+		if existing.SKey == record.SKey {
+			return adaptor.ErrAlreadyExists
+		}
+	}
+	m.sections[record.PKey] = append(m.sections[record.PKey], record)
+	return nil
+}
+func (m *memRepository) GetReportSection(pk string) ([]*models.ReportSectionRecord, error) {
+	return m.sections[pk], nil
+}
+
+func (m *memRepository) PutAttributeCache(cache *models.AttributeCache, now time.Time) error {
+	m.putAttributeCacheCalls++
+	key := cache.PKey + "/" + cache.SKey
+	if m.attrs[key] {
+		return adaptor.ErrAlreadyExists
+	}
+	m.attrs[key] = true
+	return nil
+}
+
+func (m *memRepository) GetAttributeCaches(string) ([]*models.AttributeCache, error) {
+	return nil, nil
+}
+
+func (m *memRepository) GetAttributeFilter(reportID deepalert.ReportID) (*models.AttributeFilter, error) {
+	return m.filters["attrfilter/"+string(reportID)], nil
+}
+
+func (m *memRepository) PutAttributeFilter(filter *models.AttributeFilter, expectedVersion int) error {
+	existing := m.filters[filter.PKey]
+	if (existing == nil && expectedVersion != 0) || (existing != nil && existing.Version != expectedVersion) {
+		return adaptor.ErrVersionConflict
+	}
+	m.filters[filter.PKey] = filter
+	return nil
+}
+
+func (m *memRepository) GetReportChain(reportID deepalert.ReportID) (*models.ReportChain, error) {
+	return m.chains["reportchain/"+string(reportID)], nil
+}
+
+func (m *memRepository) PutReportChain(chain *models.ReportChain, expectedVersion int) error {
+	existing := m.chains[chain.PKey]
+	if (existing == nil && expectedVersion != 0) || (existing != nil && existing.Version != expectedVersion) {
+		return adaptor.ErrVersionConflict
+	}
+	m.chains[chain.PKey] = chain
+	return nil
+}
+
+func TestPutAttributeCacheRejectsSecondWriteOfSameAttribute(t *testing.T) {
+	repo := newMemRepository()
+	svc := service.NewRepositoryService(repo, 3600)
+
+	attr := deepalert.Attribute{Type: deepalert.TypeIPAddr, Key: "SrcIP", Value: "10.0.0.1"}
+	reportID := deepalert.ReportID("report-1")
+	now := time.Now()
+
+	ok, err := svc.PutAttributeCache(reportID, attr, now)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = svc.PutAttributeCache(reportID, attr, now)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.Equal(t, 1, len(repo.attrs))
+}
+
+func TestPutAttributeCacheOnlySkipsConditionalPutWhenFilterHitsAreTrusted(t *testing.T) {
+	attr := deepalert.Attribute{Type: deepalert.TypeIPAddr, Key: "SrcIP", Value: "10.0.0.1"}
+	reportID := deepalert.ReportID("report-2")
+	now := time.Now()
+
+	t.Run("default: a hit still pays for the authoritative conditional put", func(t *testing.T) {
+		repo := newMemRepository()
+		svc := service.NewRepositoryService(repo, 3600)
+
+		ok, err := svc.PutAttributeCache(reportID, attr, now)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, 1, repo.putAttributeCacheCalls)
+
+		ok, err = svc.PutAttributeCache(reportID, attr, now)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, 2, repo.putAttributeCacheCalls, "a filter hit must not be trusted to skip the conditional put by default")
+	})
+
+	t.Run("opted in: a hit skips the conditional put entirely", func(t *testing.T) {
+		repo := newMemRepository()
+		svc := service.NewRepositoryService(repo, 3600)
+		svc.SetTrustFilterHits(true)
+
+		ok, err := svc.PutAttributeCache(reportID, attr, now)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, 1, repo.putAttributeCacheCalls)
+
+		ok, err = svc.PutAttributeCache(reportID, attr, now)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, 1, repo.putAttributeCacheCalls, "a trusted filter hit must short-circuit before reaching the conditional put")
+	})
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	stderrors "errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/m-mizutani/deepalert"
+	"github.com/m-mizutani/deepalert/internal/adaptor"
+	"github.com/m-mizutani/deepalert/internal/errors"
+	"github.com/m-mizutani/deepalert/internal/models"
+)
+
+// maxChainRetries bounds how many times extendChain retries a version
+// conflict before giving up. It's generous because every inspector fanned out for a report contends on the same
+// reportchain/<reportID> CAS item: under heavy fan-out, exhausting retries
+// turns into an unclassified, retryable handler error, and a Lambda re-run
+// of an already-saved section would otherwise duplicate it.
+const maxChainRetries = 20
+
+// chainRetryBaseDelay and chainRetryMaxDelay bound the exponential
+// backoff chainRetryDelay applies between CAS attempts.
+const (
+	chainRetryBaseDelay = 10 * time.Millisecond
+	chainRetryMaxDelay  = 500 * time.Millisecond
+)
+
+// chainRetryDelay returns a jittered exponential backoff for retry attempt
+// (0-indexed), so concurrent inspectors contending on the same report's
+// chain spread their retries out instead of hammering PutReportChain in
+// lockstep.
+func chainRetryDelay(attempt int) time.Duration {
+	backoff := chainRetryBaseDelay << uint(attempt)
+	if backoff <= 0 || backoff > chainRetryMaxDelay {
+		backoff = chainRetryMaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(backoff)))
+}
+
+func reportChainKey(reportID deepalert.ReportID) string {
+	return fmt.Sprintf("reportchain/%s", reportID)
+}
+
+// extendChain appends sectionHash to reportID's hash chain and returns the
+// resulting head and the sequence number (the chain's new version) this
+// section was chained at. Unlike testAndMarkAttributeSeen's filter CAS,
+// this is not best-effort: a section's position in the chain is exactly
+// what VerifyReport checks, so a version conflict must be retried rather
+// than ignored.
+func (x *RepositoryService) extendChain(reportID deepalert.ReportID, author, sectionHash string, now time.Time) (string, int, error) {
+	for attempt := 0; attempt < maxChainRetries; attempt++ {
+		chain, err := x.repo.GetReportChain(reportID)
+		if err != nil {
+			return "", 0, errors.Wrap(err, "Fail to get report chain").With("ReportID", reportID)
+		}
+
+		version, prevHash := 0, ""
+		if chain != nil {
+			version, prevHash = chain.Version, chain.PrevHash
+		}
+
+		head := deepalert.ComputeChainLink(prevHash, sectionHash, author)
+		seq := version + 1
+
+		updated := &models.ReportChain{
+			RecordBase: models.RecordBase{
+				PKey:      reportChainKey(reportID),
+				SKey:      "Fixed",
+				ExpiresAt: now.Add(x.ttl).Unix(),
+			},
+			PrevHash: head,
+			Version:  seq,
+		}
+
+		if err := x.repo.PutReportChain(updated, version); err != nil {
+			if stderrors.Is(err, adaptor.ErrVersionConflict) {
+				time.Sleep(chainRetryDelay(attempt))
+				continue
+			}
+			return "", 0, errors.Wrap(err, "Fail to put report chain").With("ReportID", reportID)
+		}
+
+		return head, seq, nil
+	}
+
+	return "", 0, errors.New("Fail to extend report chain: too many concurrent writers").With("ReportID", reportID)
+}
@@ -0,0 +1,62 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/deepalert"
+	"github.com/m-mizutani/deepalert/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSigner returns keyID + ":" + digest as the "signature", so tests can assert
+// on SignReport's output without a real KMS key.
+type fakeSigner struct{}
+
+func (fakeSigner) Sign(keyID string, digest []byte) ([]byte, error) {
+	return append([]byte(keyID+":"), digest...), nil
+}
+
+func TestSaveReportSectionExtendsChainAndSignReportMatches(t *testing.T) {
+	repo := newMemRepository()
+	svc := service.NewRepositoryService(repo, 3600)
+	svc.SetSigner(fakeSigner{})
+
+	reportID := deepalert.ReportID("report-5")
+	now := time.Now()
+
+	section1 := deepalert.ReportSection{ReportID: reportID, Author: "inspector-a", Content: &deepalert.ReportHost{IPAddr: []string{"10.0.0.1"}}}
+	section2 := deepalert.ReportSection{ReportID: reportID, Author: "inspector-b", Content: &deepalert.ReportHost{IPAddr: []string{"10.0.0.2"}}}
+
+	require.NoError(t, svc.SaveReportSection(section1, now))
+	require.NoError(t, svc.SaveReportSection(section2, now))
+
+	sig, err := svc.SignReport(reportID, "test-key")
+	require.NoError(t, err)
+	assert.Equal(t, "test-key", sig.KeyID)
+	assert.NotEmpty(t, sig.ChainHead)
+	assert.NotEmpty(t, sig.Signature)
+
+	sections, err := svc.FetchReportSection(reportID)
+	require.NoError(t, err)
+	require.Len(t, sections, 2)
+
+	chained, err := svc.FetchChainedSections(reportID)
+	require.NoError(t, err)
+	require.Len(t, chained, 2)
+	head := ""
+	for _, link := range chained {
+		head = deepalert.ComputeChainLink(head, link.SectionHash, link.Author)
+	}
+	assert.Equal(t, head, sig.ChainHead)
+}
+
+func TestSignReportFailsWithoutAnySavedSection(t *testing.T) {
+	repo := newMemRepository()
+	svc := service.NewRepositoryService(repo, 3600)
+	svc.SetSigner(fakeSigner{})
+
+	_, err := svc.SignReport(deepalert.ReportID("report-6"), "test-key")
+	assert.Error(t, err)
+}
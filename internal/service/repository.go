@@ -2,7 +2,9 @@ package service
 
 import (
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,17 +15,51 @@ import (
 )
 
 type RepositoryService struct {
-	repo adaptor.Repository
-	ttl  time.Duration
+	repo   adaptor.Repository
+	ttl    time.Duration
+	filter FilterStore
+	signer Signer
+
+	// trustFilterHits gates whether a cuckoo-filter hit in
+	// testAndMarkAttributeSeen is trusted to skip the authoritative
+	// conditional put on AttributeCache. See SetTrustFilterHits.
+	trustFilterHits bool
 }
 
 func NewRepositoryService(repo adaptor.Repository, ttl int64) *RepositoryService {
 	return &RepositoryService{
-		repo: repo,
-		ttl:  time.Duration(ttl) * time.Second,
+		repo:   repo,
+		ttl:    time.Duration(ttl) * time.Second,
+		filter: &repoFilterStore{repo: repo},
+		signer: NewKMSSigner(),
 	}
 }
 
+// SetFilterStore overrides where the attribute-dedup cuckoo filter is
+// persisted, e.g. to a Redis-backed FilterStore shared across many
+// inspector instances instead of the default per-Repository one.
+func (x *RepositoryService) SetFilterStore(store FilterStore) {
+	x.filter = store
+}
+
+// SetSigner overrides how SignReport signs a report's chain head, e.g. to
+// inject a fake in tests instead of calling out to AWS KMS.
+func (x *RepositoryService) SetSigner(signer Signer) {
+	x.signer = signer
+}
+
+// SetTrustFilterHits controls whether testAndMarkAttributeSeen may trust a
+// cuckoo filter hit to skip the authoritative conditional put on
+// AttributeCache. It defaults to false: the filter's ~1/65536
+// false-positive rate, compounded over the many thousands of attributes a
+// large report can carry, gives a real chance of silently dropping an
+// attribute that was never actually seen before - an unacceptable default
+// for a security tool. Call SetTrustFilterHits(true) to accept that
+// tradeoff in exchange for fewer conditional-put calls on a hot report.
+func (x *RepositoryService) SetTrustFilterHits(trust bool) {
+	x.trustFilterHits = trust
+}
+
 // -----------------------------------------------------------
 // Control alertEntry to manage AlertID to ReportID mapping
 //
@@ -47,7 +83,7 @@ func (x *RepositoryService) TakeReport(alert deepalert.Alert, now time.Time) (*d
 	}
 
 	if err := x.repo.PutAlertEntry(&entry, now); err != nil {
-		if x.repo.IsConditionalCheckErr(err) {
+		if stderrors.Is(err, adaptor.ErrAlreadyExists) {
 			existedEntry, err := x.repo.GetAlertEntry(entry.PKey, entry.SKey)
 			if err != nil {
 				return nil, errors.Wrap(err, "Fail to get cached reportID").With("AlertID", alertID)
@@ -123,32 +159,72 @@ func (x *RepositoryService) FetchAlertCache(reportID deepalert.ReportID) ([]deep
 // Control reportRecord to manage report contents by inspector
 //
 
-func toReportSectionRecord(reportID deepalert.ReportID, section *deepalert.ReportSection) (string, string) {
-	pk := fmt.Sprintf("content/%s", reportID)
-	sk := ""
-	if section != nil {
-		sk = fmt.Sprintf("%s/%s", section.Attribute.Hash(), uuid.New().String())
-	}
-	return pk, sk
+// toReportSectionRecord returns the PKey/SKey a section is stored under.
+// SKey is the section's content hash rather than a random suffix, so
+// re-saving the same section (e.g. a retried Lambda invocation) lands on
+// the same key and PutReportSectionRecord's conditional put can reject it
+// as a duplicate instead of storing it twice. Pass an empty sectionHash to
+// get just the PKey, e.g. for a GetReportSection(pk) query.
+func toReportSectionRecord(reportID deepalert.ReportID, sectionHash string) (string, string) {
+	return fmt.Sprintf("content/%s", reportID), sectionHash
 }
 
+// SaveReportSection stores section and extends reportID's tamper-evident
+// hash chain with it, so a later SignReport/deepalert.VerifyReport pair can
+// detect a section dropped or mutated after it was saved. It is safe to
+// call twice with the same section (e.g. SQS's at-least-once redelivery of
+// a task already handled): the section's deterministic key is looked up
+// *before* extending the chain, so a repeat delivery is a no-op instead of
+// appending a second link for a section FetchChainedSections will only
+// ever see once - which would make the recomputed head diverge from the
+// one that was actually signed. This check-then-act isn't a single atomic
+// operation, so two genuinely concurrent deliveries of the same section
+// can still race each other into the gap, but that is a far narrower
+// window than always extending the chain first.
 func (x *RepositoryService) SaveReportSection(section deepalert.ReportSection, now time.Time) error {
 	raw, err := json.Marshal(section)
 	if err != nil {
 		return errors.Wrapf(err, "Fail to marshal ReportSection: %v", section)
 	}
 
-	pk, sk := toReportSectionRecord(section.ReportID, &section)
+	sectionHash := deepalert.HashSection(raw)
+	pk, sk := toReportSectionRecord(section.ReportID, sectionHash)
+
+	existing, err := x.repo.GetReportSection(pk)
+	if err != nil {
+		return errors.Wrap(err, "Fail to get report sections").With("ReportID", section.ReportID)
+	}
+	for _, record := range existing {
+		if record.SKey == sk {
+			return nil
+		}
+	}
+
+	_, seq, err := x.extendChain(section.ReportID, section.Author, sectionHash, now)
+	if err != nil {
+		return err
+	}
+
 	record := &models.ReportSectionRecord{
 		RecordBase: models.RecordBase{
 			PKey:      pk,
 			SKey:      sk,
 			ExpiresAt: now.UTC().Add(x.ttl).Unix(),
+			CreatedAt: now,
 		},
-		Data: raw,
+		Data:        raw,
+		SectionHash: sectionHash,
+		Seq:         seq,
 	}
 
 	if err := x.repo.PutReportSectionRecord(record); err != nil {
+		if stderrors.Is(err, adaptor.ErrAlreadyExists) {
+			// Lost a race with another delivery of this same section
+			// between the lookup above and this insert. The record is
+			// already stored, but both deliveries have extended the chain
+			// for it (the race described on SaveReportSection).
+			return nil
+		}
 		return errors.Wrap(err, "Fail to put report record")
 	}
 
@@ -156,7 +232,7 @@ func (x *RepositoryService) SaveReportSection(section deepalert.ReportSection, n
 }
 
 func (x *RepositoryService) FetchReportSection(reportID deepalert.ReportID) ([]deepalert.ReportSection, error) {
-	pk, _ := toReportSectionRecord(reportID, nil)
+	pk, _ := toReportSectionRecord(reportID, "")
 
 	records, err := x.repo.GetReportSection(pk)
 	if err != nil {
@@ -176,6 +252,38 @@ func (x *RepositoryService) FetchReportSection(reportID deepalert.ReportID) ([]d
 	return sections, nil
 }
 
+// FetchChainedSections returns reportID's saved sections as
+// deepalert.ChainedSection, ordered by the Seq each was chained at, ready
+// to pass to deepalert.VerifyReport. GetReportSection itself makes no
+// ordering guarantee - SQL backends in particular may return rows in
+// whatever order their query planner prefers - so this is the only
+// correct way to reconstruct chain order for verification.
+func (x *RepositoryService) FetchChainedSections(reportID deepalert.ReportID) ([]deepalert.ChainedSection, error) {
+	pk, _ := toReportSectionRecord(reportID, "")
+
+	records, err := x.repo.GetReportSection(pk)
+	if err != nil {
+		return nil, errors.Wrap(err, "Fail to get report sections").With("ReportID", reportID)
+	}
+
+	sort.Slice(records, func(i, j int) bool { return records[i].Seq < records[j].Seq })
+
+	chained := make([]deepalert.ChainedSection, 0, len(records))
+	for _, record := range records {
+		var section deepalert.ReportSection
+		if err := json.Unmarshal(record.Data, &section); err != nil {
+			return nil, errors.Wrapf(err, "Fail to unmarshal report content: %v %s", record, string(record.Data))
+		}
+
+		chained = append(chained, deepalert.ChainedSection{
+			SectionHash: record.SectionHash,
+			Author:      section.Author,
+		})
+	}
+
+	return chained, nil
+}
+
 // -----------------------------------------------------------
 // Control attribute cache to prevent duplicated invocation of Inspector with same attribute
 //
@@ -187,6 +295,10 @@ func toAttributeCacheKey(reportID deepalert.ReportID) string {
 // PutAttributeCache puts attributeCache to DB and returns true. If the attribute alrady exists,
 // it returns false.
 func (x *RepositoryService) PutAttributeCache(reportID deepalert.ReportID, attr deepalert.Attribute, now time.Time) (bool, error) {
+	if x.testAndMarkAttributeSeen(reportID, attr, now) {
+		return false, nil
+	}
+
 	var ts time.Time
 	if attr.Timestamp != nil {
 		ts = *attr.Timestamp
@@ -208,7 +320,7 @@ func (x *RepositoryService) PutAttributeCache(reportID deepalert.ReportID, attr
 	}
 
 	if err := x.repo.PutAttributeCache(cache, now); err != nil {
-		if x.repo.IsConditionalCheckErr(err) {
+		if stderrors.Is(err, adaptor.ErrAlreadyExists) {
 			// The attribute already exists
 			return false, nil
 		}
@@ -242,4 +354,4 @@ func (x *RepositoryService) FetchAttributeCache(reportID deepalert.ReportID) ([]
 	}
 
 	return attrs, nil
-}
\ No newline at end of file
+}
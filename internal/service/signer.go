@@ -0,0 +1,72 @@
+package service
+
+import (
+	"crypto/sha256"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/m-mizutani/deepalert"
+	"github.com/m-mizutani/deepalert/internal/errors"
+)
+
+// Signer produces the signature SignReport attaches to a finalized
+// Report's chain head. KMSSigner is the production implementation; tests
+// can substitute a fake via SetSigner.
+type Signer interface {
+	Sign(keyID string, digest []byte) (signature []byte, err error)
+}
+
+// KMSSigner signs digests with an AWS KMS asymmetric ECDSA_SHA_256 key.
+type KMSSigner struct {
+	client kmsiface.KMSAPI
+}
+
+// NewKMSSigner opens a KMSSigner in the AWS session's default region.
+func NewKMSSigner() *KMSSigner {
+	return &KMSSigner{client: kms.New(session.New())}
+}
+
+// Sign implements Signer.
+func (x *KMSSigner) Sign(keyID string, digest []byte) ([]byte, error) {
+	resp, err := x.client.Sign(&kms.SignInput{
+		KeyId:            aws.String(keyID),
+		Message:          digest,
+		MessageType:      aws.String(kms.MessageTypeDigest),
+		SigningAlgorithm: aws.String(kms.SigningAlgorithmSpecEcdsaSha256),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Fail to sign with KMS").With("KeyID", keyID)
+	}
+
+	return resp.Signature, nil
+}
+
+// SignReport signs reportID's current chain head with keyID, returning the
+// ReportSignature envelope to attach to the finalized deepalert.Report. It
+// reads the chain rather than extending it: by the time a report is
+// finalized every inspector's section has already been chained via
+// SaveReportSection.
+func (x *RepositoryService) SignReport(reportID deepalert.ReportID, keyID string) (*deepalert.ReportSignature, error) {
+	chain, err := x.repo.GetReportChain(reportID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Fail to get report chain").With("ReportID", reportID)
+	}
+	if chain == nil {
+		return nil, errors.New("Report has no chained sections to sign").With("ReportID", reportID)
+	}
+
+	digest := sha256.Sum256([]byte(chain.PrevHash))
+	signature, err := x.signer.Sign(keyID, digest[:])
+	if err != nil {
+		return nil, errors.Wrap(err, "Fail to sign report chain head").With("ReportID", reportID)
+	}
+
+	return &deepalert.ReportSignature{
+		ChainHead: chain.PrevHash,
+		Signature: signature,
+		KeyID:     keyID,
+		Alg:       kms.SigningAlgorithmSpecEcdsaSha256,
+	}, nil
+}
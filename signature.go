@@ -0,0 +1,74 @@
+package deepalert
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// ReportSignature is the tamper-evidence envelope attached to a finalized
+// Report. ChainHead is the terminal link of the report's hash chain (see
+// ComputeChainLink) and Signature is ChainHead signed by the AWS KMS
+// asymmetric key identified by KeyID.
+type ReportSignature struct {
+	ChainHead string
+	Signature []byte
+	KeyID     string
+	Alg       string
+}
+
+// ChainedSection is the minimal per-section information VerifyReport needs
+// to recompute a report's hash chain: the section's content hash (see
+// HashSection) and the inspector that produced it. Sections must be given
+// in the exact order they were originally chained (see
+// RepositoryService.FetchChainedSections). The chain deliberately folds in
+// no timestamp: a reproducible one can't be round-tripped exactly through
+// every SQL backend (MySQL's DATETIME drops sub-second digits, Postgres'
+// TIMESTAMPTZ is microseconds against Go's nanoseconds), which would make
+// VerifyReport false-negative on an untampered report.
+type ChainedSection struct {
+	SectionHash string
+	Author      string
+}
+
+// HashSection returns the hex-encoded sha256 of a report section's
+// marshaled content, the per-link digest ComputeChainLink folds into a
+// report's hash chain.
+func HashSection(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ComputeChainLink extends a report's hash chain with one more section:
+// the new head is H(prevHash || sectionHash || author). An empty prevHash
+// denotes the first section chained for a report.
+func ComputeChainLink(prevHash, sectionHash, author string) string {
+	h := sha256.New()
+	h.Write([]byte(prevHash))
+	h.Write([]byte(sectionHash))
+	h.Write([]byte(author))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// VerifyReport recomputes a report's hash chain from chain and checks
+// sig.Signature against the resulting head using pubKey. It returns false,
+// nil (rather than an error) whenever the chain or the signature doesn't
+// match, so a SIEM or ticketing integration can treat "verification
+// failed" and "report was tampered with" the same way. chain must be
+// ordered exactly as the sections were originally chained (see
+// RepositoryService.FetchChainedSections) - the hash chain is
+// order-sensitive, so any other ordering false-negatives an untampered
+// report.
+func VerifyReport(sig ReportSignature, chain []ChainedSection, pubKey *ecdsa.PublicKey) (bool, error) {
+	head := ""
+	for _, link := range chain {
+		head = ComputeChainLink(head, link.SectionHash, link.Author)
+	}
+
+	if head != sig.ChainHead {
+		return false, nil
+	}
+
+	digest := sha256.Sum256([]byte(head))
+	return ecdsa.VerifyASN1(pubKey, digest[:], sig.Signature), nil
+}